@@ -14,6 +14,8 @@ var (
 	buildValidate bool
 	buildOnly     []string // 仅执行指定名称的任务
 	buildServer   string   // 仅部署到指定服务器
+	buildTags     []string // 仅部署到带有指定标签的服务器
+	buildGroups   []string // 仅部署到指定服务器组中的服务器
 )
 
 // StageRange 阶段范围
@@ -39,7 +41,10 @@ var buildCmd = &cobra.Command{
 
 使用 --only 参数可以只执行指定名称的任务（支持多个）:
   --only "用户服务镜像"           只执行名为"用户服务镜像"的任务
-  --only "用户服务" --only "订单服务"  同时执行两个指定的任务`,
+  --only "用户服务" --only "订单服务"  同时执行两个指定的任务
+
+使用 --server / --server-tag / --server-group 限制远程任务的目标服务器:
+同一参数的多个值取并集，不同参数之间取交集。`,
 	Example: `  xbuilder build              # 运行全部阶段
   xbuilder build 2            # 只运行第 2 个阶段
   xbuilder build 1-3          # 运行第 1 到第 3 个阶段
@@ -47,7 +52,9 @@ var buildCmd = &cobra.Command{
   xbuilder build -3           # 运行第 1 到第 3 个阶段
   xbuilder build -v           # 先验证配置，再运行
   xbuilder build --only "用户服务镜像"  # 只执行指定任务
-  xbuilder build 2 --only "用户服务"   # 在第 2 阶段中只执行指定任务`,
+  xbuilder build 2 --only "用户服务"   # 在第 2 阶段中只执行指定任务
+  xbuilder build --server-tag canary   # 只部署到带 canary 标签的服务器
+  xbuilder build --server-group web --server-tag cn  # 只部署到 web 组中带 cn 标签的服务器`,
 	Args:              cobra.MaximumNArgs(1),
 	RunE:              runBuild,
 	ValidArgsFunction: completeBuildStages,
@@ -58,10 +65,14 @@ func init() {
 	buildCmd.Flags().BoolVarP(&buildValidate, "validate", "v", false, "构建前先验证配置文件")
 	buildCmd.Flags().StringArrayVarP(&buildOnly, "only", "o", nil, "只执行指定名称的任务（可多次使用）")
 	buildCmd.Flags().StringVarP(&buildServer, "server", "s", "", "仅部署到指定服务器名 (默认全部服务器)")
+	buildCmd.Flags().StringArrayVar(&buildTags, "server-tag", nil, "仅部署到带有指定标签的服务器（可多次使用）")
+	buildCmd.Flags().StringArrayVar(&buildGroups, "server-group", nil, "仅部署到指定服务器组中的服务器（可多次使用）")
 
 	// 注册 --only 参数的补全函数
 	_ = buildCmd.RegisterFlagCompletionFunc("only", completeTaskNames)
 	_ = buildCmd.RegisterFlagCompletionFunc("server", completeServerNames)
+	_ = buildCmd.RegisterFlagCompletionFunc("server-tag", completeServerTags)
+	_ = buildCmd.RegisterFlagCompletionFunc("server-group", completeServerGroups)
 }
 
 // completeBuildStages 为 build 命令提供阶段补全
@@ -157,6 +168,11 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	// 获取配置文件路径
 	configFile := GetConfigFile()
 
+	vars, err := GetVariables()
+	if err != nil {
+		return err
+	}
+
 	// 创建构建选项
 	opts := app.BuildOptions{
 		ConfigFile:   configFile,
@@ -165,6 +181,10 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		StageEnd:     -1,
 		OnlyTasks:    buildOnly, // 仅执行指定任务
 		TargetServer: buildServer,
+		TargetTags:   buildTags,
+		TargetGroups: buildGroups,
+		Profile:      GetProfile(),
+		Variables:    vars,
 	}
 
 	if stageRange != nil {
@@ -179,7 +199,12 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	// 如果需要先验证
 	if buildValidate {
 		fmt.Println("🔍 验证配置文件...")
-		if err := app.ValidateConfig(configFile); err != nil {
+		if err := app.RunValidate(app.ValidateOptions{
+			ConfigFile: configFile,
+			Format:     app.FormatText,
+			Profile:    opts.Profile,
+			Variables:  opts.Variables,
+		}); err != nil {
 			return err
 		}
 		fmt.Println("✅ 配置验证通过")
@@ -214,6 +239,51 @@ func completeServerNames(cmd *cobra.Command, args []string, toComplete string) (
 	return completions, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completeServerTags 为 --server-tag 参数提供标签补全
+func completeServerTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg := loadCompletionConfig()
+	if cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, tag := range cfg.TagNames() {
+		completions = append(completions, fmt.Sprintf("%s\t%s", tag, strings.Join(cfg.TaggedServers(tag), ", ")))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeServerGroups 为 --server-group 参数提供服务器组补全
+func completeServerGroups(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg := loadCompletionConfig()
+	if cfg == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, group := range cfg.GroupNames() {
+		completions = append(completions, fmt.Sprintf("%s\t%s", group, strings.Join(cfg.GroupMembers(group), ", ")))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// loadCompletionConfig 加载补全所需的配置（找不到或加载失败时返回 nil）
+func loadCompletionConfig() *config.Config {
+	configFile := GetConfigFile()
+	if configFile == "" {
+		configFile, _ = config.FindConfigFile()
+	}
+	if configFile == "" {
+		return nil
+	}
+
+	cfg, err := config.NewLoader(configFile).Load()
+	if err != nil {
+		return nil
+	}
+	return cfg
+}
+
 // parseStageRange 解析阶段范围参数
 func parseStageRange(arg string) (*StageRange, error) {
 	arg = strings.TrimSpace(arg)
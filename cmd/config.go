@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/xiaolfeng/builder-cli/internal/app"
+	"github.com/xiaolfeng/builder-cli/internal/config"
+)
+
+var (
+	configPrintResolved bool
+	configPrintExplain  bool
+	configPrintDiff     string
+)
+
+// configCmd config 父命令
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "查看配置",
+	Long:  `查看 xbuilder.yaml 配置及变量替换结果。`,
+}
+
+// configPrintCmd config print 命令
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "输出配置（可选输出变量替换后的结果）",
+	Long: `输出配置文件内容。
+
+使用 --resolved 输出应用 profile、variables、--var 覆盖和内置变量后的最终配置，
+密码、Token 等敏感值会被脱敏显示；未解析的变量引用保持原样，并在 stderr 中提示。
+
+变量优先级: --var > profile > variables > 内置变量，均未定义时回退到环境变量。
+内置变量: ${project.name}、${profile}、${config.dir}、${host.os}、${host.arch}`,
+	Example: `  xbuilder config print --resolved                  # 输出替换后的配置
+  xbuilder config print --explain                   # 标注每个替换值的来源
+  xbuilder config print --profile prod --explain    # 查看 prod profile 的解析结果
+  xbuilder config print --var APP_VERSION=2.0.0 --resolved
+  xbuilder config print --diff prod                 # 对比默认配置与 prod profile
+  xbuilder config print --profile staging --diff prod`,
+	Args: cobra.NoArgs,
+	RunE: runConfigPrint,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPrintCmd)
+
+	configPrintCmd.Flags().BoolVar(&configPrintResolved, "resolved", false, "输出变量替换后的配置")
+	configPrintCmd.Flags().BoolVar(&configPrintExplain, "explain", false, "以注释标注每个替换值的来源（隐含 --resolved）")
+	configPrintCmd.Flags().StringVar(&configPrintDiff, "diff", "", "与指定 profile 的解析结果对比（隐含 --resolved）")
+
+	_ = configPrintCmd.RegisterFlagCompletionFunc("diff", completeProfileNames)
+	_ = rootCmd.RegisterFlagCompletionFunc("profile", completeProfileNames)
+}
+
+func runConfigPrint(cmd *cobra.Command, args []string) error {
+	vars, err := GetVariables()
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	return app.RunConfigPrint(app.ConfigPrintOptions{
+		ConfigFile:  GetConfigFile(),
+		Profile:     GetProfile(),
+		Variables:   vars,
+		Resolved:    configPrintResolved,
+		Explain:     configPrintExplain,
+		DiffProfile: configPrintDiff,
+	})
+}
+
+// completeProfileNames 为 --profile / --diff 参数提供 profile 名称补全
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	configFile := GetConfigFile()
+	if configFile == "" {
+		configFile, _ = config.FindConfigFile()
+	}
+
+	if configFile == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	loader := config.NewLoader(configFile)
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cfg.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/xiaolfeng/builder-cli/internal/app"
+	"github.com/xiaolfeng/builder-cli/internal/config"
+)
+
+var (
+	migrateDryRun bool
+	migrateYes    bool
+)
+
+// migrateCmd migrate 命令
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "将配置文件迁移到当前版本",
+	Long: `将旧版本的 xbuilder.yaml 改写为当前配置格式（版本 ` + config.CurrentVersion + `）。
+
+迁移会保留注释和原有格式，写入前显示差异并请求确认。`,
+	Example: `  xbuilder migrate              # 显示差异并确认后写入
+  xbuilder migrate --dry-run    # 仅显示差异
+  xbuilder migrate -y           # 不确认直接写入`,
+	Args: cobra.NoArgs,
+	RunE: runMigrate,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "仅显示差异，不写入文件")
+	migrateCmd.Flags().BoolVarP(&migrateYes, "yes", "y", false, "跳过确认直接写入")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+	return app.RunMigrate(app.MigrateOptions{
+		ConfigFile: GetConfigFile(),
+		DryRun:     migrateDryRun,
+		Yes:        migrateYes,
+	})
+}
@@ -3,13 +3,16 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/xiaolfeng/builder-cli/pkg/version"
 )
 
 var (
-	cfgFile string
+	cfgFile    string
+	cfgProfile string   // 激活的 profile
+	cfgVars    []string // 命令行覆盖的变量 KEY=VALUE
 )
 
 // rootCmd 根命令
@@ -43,6 +46,8 @@ func Execute() {
 func init() {
 	// 全局 flags
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "配置文件路径 (默认: xbuilder.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgProfile, "profile", "", "激活的 profile（覆盖 variables）")
+	rootCmd.PersistentFlags().StringArrayVar(&cfgVars, "var", nil, "覆盖变量 KEY=VALUE（可多次使用，优先级最高）")
 
 	// 版本信息格式
 	rootCmd.SetVersionTemplate(fmt.Sprintf(`xbuilder version %s
@@ -55,3 +60,24 @@ GitHub: https://github.com/XiaoLFeng/builder-cli
 func GetConfigFile() string {
 	return cfgFile
 }
+
+// GetProfile 获取激活的 profile
+func GetProfile() string {
+	return cfgProfile
+}
+
+// GetVariables 获取命令行覆盖的变量
+func GetVariables() (map[string]string, error) {
+	if len(cfgVars) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(cfgVars))
+	for _, kv := range cfgVars {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("无效的变量参数: %s (格式: KEY=VALUE)", kv)
+		}
+		vars[strings.TrimSpace(key)] = value
+	}
+	return vars, nil
+}
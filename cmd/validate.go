@@ -7,27 +7,57 @@ import (
 	"github.com/xiaolfeng/builder-cli/internal/app"
 )
 
+var (
+	validateFormat string
+)
+
 // validateCmd validate 命令
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "验证配置文件",
-	Long:  `验证 xbuilder.yaml 配置文件的语法和内容是否正确。`,
+	Long: `验证 xbuilder.yaml 配置文件的语法和内容是否正确。
+
+错误以 file:line:col: message 格式输出，并显示出错位置的代码片段，
+编辑器和 CI 可直接识别并定位。使用 --format json 输出机器可读的结果。`,
 	Example: `  xbuilder validate                 # 验证默认配置文件
-  xbuilder validate -c custom.yaml  # 验证指定配置文件`,
+  xbuilder validate -c custom.yaml  # 验证指定配置文件
+  xbuilder validate --format json   # 以 JSON 格式输出验证结果`,
 	RunE: runValidate,
 }
 
 func init() {
 	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVar(&validateFormat, "format", app.FormatText, "输出格式 (text/json)")
+
+	_ = validateCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{app.FormatText, app.FormatJSON}, cobra.ShellCompDirectiveNoFileComp
+	})
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
 	configFile := GetConfigFile()
 
-	if err := app.ValidateConfig(configFile); err != nil {
+	// JSON 模式下 stdout 仅输出结果，错误不再附带用法说明
+	if validateFormat == app.FormatJSON {
+		cmd.SilenceUsage = true
+	}
+
+	vars, err := GetVariables()
+	if err != nil {
 		return err
 	}
 
-	fmt.Println("✅ 配置文件验证通过")
+	if err := app.RunValidate(app.ValidateOptions{
+		ConfigFile: configFile,
+		Format:     validateFormat,
+		Profile:    GetProfile(),
+		Variables:  vars,
+	}); err != nil {
+		return err
+	}
+
+	if validateFormat != app.FormatJSON {
+		fmt.Println("✅ 配置文件验证通过")
+	}
 	return nil
 }
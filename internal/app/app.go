@@ -1,13 +1,18 @@
 package app
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/xiaolfeng/builder-cli/internal/config"
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/builtin" // 注册内置任务类型
+	"github.com/xiaolfeng/builder-cli/internal/pipeline"
 	"github.com/xiaolfeng/builder-cli/internal/tui"
 )
 
@@ -19,6 +24,11 @@ type BuildOptions struct {
 	StageEnd     int      // 结束阶段 (0-based), -1 表示到最后
 	OnlyTasks    []string // 仅执行指定名称的任务
 	TargetServer string   // 仅部署到指定服务器（可选）
+	TargetTags   []string // 仅部署到带有任一标签的服务器（可选）
+	TargetGroups []string // 仅部署到任一服务器组中的服务器（可选）
+
+	Profile   string            // 激活的 profile（可选）
+	Variables map[string]string // 命令行覆盖的变量（--var）
 }
 
 // RunBuild 运行构建
@@ -41,17 +51,22 @@ func RunBuild(opts BuildOptions) error {
 	fmt.Printf("📄 使用配置文件: %s\n", configPath)
 
 	// 加载配置
-	loader := config.NewLoader(configPath)
-	cfg, err := loader.Load()
+	cfg, err := loadConfig(configPath, opts.Profile, opts.Variables)
 	if err != nil {
 		return fmt.Errorf("❌ 加载配置失败: %v", err)
 	}
+	if cfg.Profile() != "" {
+		fmt.Printf("🧩 激活 profile: %s\n", cfg.Profile())
+	}
 
 	// 验证配置
 	validator := config.NewValidator(cfg)
 	if err := validator.Validate(); err != nil {
 		return fmt.Errorf("❌ 配置验证失败:\n%v", err)
 	}
+	for _, w := range validator.Warnings() {
+		fmt.Printf("⚠️  [WARN] %v\n", w)
+	}
 
 	// 处理阶段范围
 	totalStages := len(cfg.Pipeline)
@@ -89,30 +104,41 @@ func RunBuild(opts BuildOptions) error {
 		fmt.Printf("🎯 仅执行任务: %v\n", opts.OnlyTasks)
 	}
 
-	// 过滤服务器（--server 参数，仅作用于 SSH 部署任务）
-	if opts.TargetServer != "" {
-		if _, ok := cfg.Servers[opts.TargetServer]; !ok {
-			return fmt.Errorf("❌ 服务器不存在: %s", opts.TargetServer)
+	// 过滤服务器（--server / --server-tag / --server-group 参数，仅作用于远程任务）
+	if opts.TargetServer != "" || len(opts.TargetTags) > 0 || len(opts.TargetGroups) > 0 {
+		servers, err := selectServers(cfg, opts)
+		if err != nil {
+			return fmt.Errorf("❌ %v", err)
 		}
-		cfg.Pipeline = filterTasksByServer(cfg.Pipeline, opts.TargetServer)
+		cfg.RestrictServers(servers)
+		cfg.Pipeline = filterTasksByServer(cfg)
 		if countTotalTasks(cfg.Pipeline) == 0 {
-			return fmt.Errorf("❌ 没有找到匹配服务器 [%s] 的任务", opts.TargetServer)
+			return fmt.Errorf("❌ 没有找到匹配服务器 [%s] 的任务", strings.Join(servers, ", "))
 		}
-		fmt.Printf("🎯 仅部署到服务器: %s\n", opts.TargetServer)
+		fmt.Printf("🎯 仅部署到服务器: %s\n", strings.Join(servers, ", "))
 	}
 
 	fmt.Printf("✅ 配置验证通过\n")
 	fmt.Printf("📦 项目: %s\n", cfg.Project.Name)
 	fmt.Printf("🔄 阶段数: %d\n\n", len(cfg.Pipeline))
 
+	// 创建流水线（解码任务配置，展开 auto_scan 等子任务）
+	pl, err := pipeline.New(cfg)
+	if err != nil {
+		return fmt.Errorf("❌ 创建流水线失败: %v", err)
+	}
+
 	// 显示将要执行的阶段
-	for i, stage := range cfg.Pipeline {
+	for i, stage := range pl.GetStages() {
 		fmt.Printf("   %d. %s\n", i+1, stage.Name)
+		if len(stage.Tasks) > len(cfg.Pipeline[i].Tasks) {
+			fmt.Printf("      🔍 共 %d 个任务（含自动扫描展开）\n", len(stage.Tasks))
+		}
 	}
 	fmt.Println()
 
 	// 创建 TUI Model
-	model := tui.New(cfg)
+	model := tui.New(cfg, pl)
 
 	// 创建 tea.Program
 	p := tea.NewProgram(&model, tea.WithAltScreen())
@@ -130,12 +156,16 @@ func RunBuild(opts BuildOptions) error {
 	if m, ok := finalModel.(*tui.Model); ok && m.IsFailed() {
 		// 显示美化的错误信息
 		printBuildError(*m)
+		if path := pl.Summary().ManifestPath; path != "" {
+			fmt.Printf("📝 已完成部分的镜像清单: %s\n\n", path)
+		}
 		return fmt.Errorf("构建失败")
 	}
 
 	// 成功完成
 	fmt.Println()
 	fmt.Println("✅ 构建成功完成！")
+	printRunSummary(pl.Summary())
 
 	return nil
 }
@@ -267,17 +297,63 @@ func filterTasks(pipeline []config.Stage, onlyTasks []string) []config.Stage {
 	return result
 }
 
-// filterTasksByServer 仅保留目标服务器的 SSH 任务，其他类型任务保留
-func filterTasksByServer(pipeline []config.Stage, server string) []config.Stage {
+// selectServers 按 --server、--server-tag、--server-group 选择目标服务器（按名称排序）
+// 同一参数的多个值取并集，不同参数之间取交集，如 --server-tag blue --server-group web 表示 web 组中带 blue 标签的服务器
+func selectServers(cfg *config.Config, opts BuildOptions) ([]string, error) {
+	var sets [][]string
+	if opts.TargetServer != "" {
+		if _, ok := cfg.Servers[opts.TargetServer]; !ok {
+			return nil, fmt.Errorf("服务器不存在: %s", opts.TargetServer)
+		}
+		sets = append(sets, []string{opts.TargetServer})
+	}
+	if len(opts.TargetTags) > 0 {
+		var tagged []string
+		for _, tag := range opts.TargetTags {
+			if !cfg.HasTag(tag) {
+				return nil, fmt.Errorf("没有服务器设置标签: %s (可用: %s)", tag, strings.Join(cfg.TagNames(), ", "))
+			}
+			tagged = append(tagged, cfg.TaggedServers(tag)...)
+		}
+		sets = append(sets, tagged)
+	}
+	if len(opts.TargetGroups) > 0 {
+		var members []string
+		for _, group := range opts.TargetGroups {
+			if !cfg.HasGroup(group) {
+				return nil, fmt.Errorf("服务器组不存在: %s (可用: %s)", group, strings.Join(cfg.GroupNames(), ", "))
+			}
+			members = append(members, cfg.GroupMembers(group)...)
+		}
+		sets = append(sets, members)
+	}
+
+	var selected []string
+	for _, name := range cfg.ServerNames() {
+		matched := true
+		for _, set := range sets {
+			matched = matched && slices.Contains(set, name)
+		}
+		if matched {
+			selected = append(selected, name)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("没有同时满足 --server、--server-tag、--server-group 条件的服务器")
+	}
+	return selected, nil
+}
+
+// filterTasksByServer 仅保留连接目标服务器（cfg.RestrictServers）的远程任务，其他类型任务保留
+// 多服务器任务保留后只在目标服务器上执行
+func filterTasksByServer(cfg *config.Config) []config.Stage {
 	var result []config.Stage
-	for _, stage := range pipeline {
+	for _, stage := range cfg.Pipeline {
 		var filtered []config.Task
 		for _, task := range stage.Tasks {
-			if task.Type != config.TaskTypeSSH {
-				filtered = append(filtered, task)
-				continue
-			}
-			if task.Config.Server == server {
+			settings, err := task.Settings()
+			_, targeted := settings.(config.ServerTargeted)
+			if err != nil || !targeted || len(cfg.TaskServers(settings)) > 0 {
 				filtered = append(filtered, task)
 			}
 		}
@@ -299,40 +375,192 @@ func countTotalTasks(pipeline []config.Stage) int {
 	return count
 }
 
+// ValidateOptions 验证选项
+type ValidateOptions struct {
+	ConfigFile string            // 配置文件路径
+	Format     string            // 输出格式: text | json
+	Profile    string            // 激活的 profile（可选）
+	Variables  map[string]string // 命令行覆盖的变量（--var）
+}
+
+// 验证输出格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // ValidateConfig 验证配置文件
 func ValidateConfig(configPath string) error {
-	// 查找配置文件
+	return RunValidate(ValidateOptions{ConfigFile: configPath, Format: FormatText})
+}
+
+// RunValidate 按指定格式验证配置文件并输出结果
+func RunValidate(opts ValidateOptions) error {
+	switch opts.Format {
+	case "", FormatText:
+		return validateText(opts)
+	case FormatJSON:
+		return validateJSON(opts)
+	default:
+		return fmt.Errorf("❌ 不支持的输出格式: %s (支持: text, json)", opts.Format)
+	}
+}
+
+// loadConfig 按 profile 和命令行变量加载配置
+func loadConfig(configPath, profile string, vars map[string]string) (*config.Config, error) {
+	loader := config.NewLoader(configPath)
+	loader.SetProfile(profile)
+	loader.SetVariables(vars)
+	return loader.Load()
+}
+
+// resolveConfigPath 查找或检查配置文件路径
+func resolveConfigPath(configPath string) (string, error) {
 	if configPath == "" {
-		var err error
-		configPath, err = config.FindConfigFile()
-		if err != nil {
-			return fmt.Errorf("❌ %v", err)
-		}
-	} else {
-		// 检查指定的配置文件是否存在
-		if _, err := os.Stat(configPath); os.IsNotExist(err) {
-			return fmt.Errorf("❌ 配置文件不存在: %s", configPath)
-		}
+		return config.FindConfigFile()
+	}
+	// 检查指定的配置文件是否存在
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("配置文件不存在: %s", configPath)
+	}
+	return configPath, nil
+}
+
+// validateText 以文本格式验证配置，出错时打印带代码片段的错误
+func validateText(opts ValidateOptions) error {
+	configPath, err := resolveConfigPath(opts.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
 	}
 
 	fmt.Printf("🔍 验证配置文件: %s\n", configPath)
 
 	// 加载配置
-	loader := config.NewLoader(configPath)
-	cfg, err := loader.Load()
+	cfg, err := loadConfig(configPath, opts.Profile, opts.Variables)
 	if err != nil {
 		return fmt.Errorf("❌ 加载配置失败: %v", err)
 	}
 
 	// 验证配置
 	validator := config.NewValidator(cfg)
-	if err := validator.Validate(); err != nil {
+	err = validator.Validate()
+	warnings := validator.Warnings()
+	if err != nil {
+		var verrs config.ValidationErrors
+		if errors.As(err, &verrs) {
+			printValidationErrors(cfg, append(verrs, warnings...))
+			return fmt.Errorf("❌ 配置验证失败: 共 %d 个错误", len(verrs))
+		}
 		return fmt.Errorf("❌ 配置验证失败:\n%v", err)
 	}
 
+	if len(warnings) > 0 {
+		printValidationErrors(cfg, warnings)
+	}
+	return nil
+}
+
+// validateReport JSON 格式的验证结果
+type validateReport struct {
+	File     string                   `json:"file"`
+	Valid    bool                     `json:"valid"`
+	Errors   []config.ValidationError `json:"errors"`
+	Warnings []config.ValidationError `json:"warnings"`
+}
+
+// validateJSON 以 JSON 格式验证配置，输出到 stdout 供工具消费
+func validateJSON(opts ValidateOptions) error {
+	report := validateReport{Errors: []config.ValidationError{}, Warnings: []config.ValidationError{}}
+
+	configPath, err := resolveConfigPath(opts.ConfigFile)
+	if err != nil {
+		report.Errors = append(report.Errors, config.ValidationError{Message: err.Error(), Severity: config.SeverityError})
+		return writeValidateReport(report)
+	}
+	report.File = configPath
+
+	cfg, err := loadConfig(configPath, opts.Profile, opts.Variables)
+	if err != nil {
+		report.Errors = append(report.Errors, config.ValidationError{File: configPath, Message: err.Error(), Severity: config.SeverityError})
+		return writeValidateReport(report)
+	}
+
+	validator := config.NewValidator(cfg)
+	if err := validator.Validate(); err != nil {
+		var verrs config.ValidationErrors
+		if errors.As(err, &verrs) {
+			report.Errors = append(report.Errors, verrs...)
+		} else {
+			report.Errors = append(report.Errors, config.ValidationError{File: configPath, Message: err.Error(), Severity: config.SeverityError})
+		}
+	}
+	report.Warnings = append(report.Warnings, validator.Warnings()...)
+
+	return writeValidateReport(report)
+}
+
+// writeValidateReport 输出 JSON 验证结果，存在错误时返回非 nil 以设置退出码
+func writeValidateReport(report validateReport) error {
+	report.Valid = len(report.Errors) == 0
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("输出验证结果失败: %w", err)
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("配置验证失败: 共 %d 个错误", len(report.Errors))
+	}
 	return nil
 }
 
+// printValidationErrors 打印验证错误（或警告）及出错位置的代码片段（配置文件或 inventory 文件）
+func printValidationErrors(cfg *config.Config, verrs config.ValidationErrors) {
+	locationStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	errorMsgStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B"))
+	warningMsgStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFE66D"))
+	gutterStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#626262"))
+	highlightStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFE66D"))
+	caretStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FF6B6B"))
+
+	fmt.Println()
+	for _, verr := range verrs {
+		location := verr.Field
+		if verr.File != "" && verr.Line > 0 {
+			location = fmt.Sprintf("%s:%d:%d", verr.File, verr.Line, verr.Column)
+		}
+		msg := errorMsgStyle.Render(verr.Field + ": " + verr.Message)
+		if verr.Severity == config.SeverityWarning {
+			msg = warningMsgStyle.Render("warning: " + verr.Field + ": " + verr.Message)
+		}
+		fmt.Printf("%s %s\n", locationStyle.Render(location+":"), msg)
+
+		frame := cfg.SourceOf(verr.File).CodeFrame(verr.Position(), 2)
+		if len(frame) == 0 {
+			fmt.Println()
+			continue
+		}
+
+		width := len(fmt.Sprint(frame[len(frame)-1].Number))
+		for _, fl := range frame {
+			marker := "  "
+			text := fl.Text
+			if fl.Highlight {
+				marker = "> "
+				text = highlightStyle.Render(text)
+			}
+			fmt.Printf("%s%s %s\n", marker, gutterStyle.Render(fmt.Sprintf("%*d |", width, fl.Number)), text)
+
+			if fl.Highlight && verr.Column > 0 {
+				padding := strings.Repeat(" ", width+5+verr.Column-1)
+				fmt.Printf("%s%s\n", padding, caretStyle.Render("^"))
+			}
+		}
+		fmt.Println()
+	}
+}
+
 // App 应用程序 (保留向后兼容)
 type App struct {
 	config     *config.Config
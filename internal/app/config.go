@@ -0,0 +1,157 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/textdiff"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPrintOptions config print 选项
+type ConfigPrintOptions struct {
+	ConfigFile  string            // 配置文件路径
+	Profile     string            // 激活的 profile（可选）
+	Variables   map[string]string // 命令行覆盖的变量（--var）
+	Resolved    bool              // 输出变量替换后的配置
+	Explain     bool              // 标注每个替换值的来源（隐含 Resolved）
+	DiffProfile string            // 与指定 profile 的解析结果对比（隐含 Resolved）
+}
+
+// RunConfigPrint 输出配置文件，可选输出变量替换后的结果
+func RunConfigPrint(opts ConfigPrintOptions) error {
+	configPath, err := resolveConfigPath(opts.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	if opts.DiffProfile != "" {
+		return printProfileDiff(configPath, opts)
+	}
+
+	if !opts.Resolved && !opts.Explain {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("❌ 读取配置文件失败: %v", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	cfg, err := loadConfig(configPath, opts.Profile, opts.Variables)
+	if err != nil {
+		return fmt.Errorf("❌ 加载配置失败: %v", err)
+	}
+
+	out, unresolved, err := renderResolved(cfg, opts.Explain)
+	if err != nil {
+		return fmt.Errorf("❌ 解析配置失败: %v", err)
+	}
+	fmt.Print(out)
+
+	printUnresolved(unresolved)
+	return nil
+}
+
+// printProfileDiff 对比两个 profile 的解析结果
+func printProfileDiff(configPath string, opts ConfigPrintOptions) error {
+	from, _, err := resolveProfile(configPath, opts.Profile, opts.Variables)
+	if err != nil {
+		return err
+	}
+	to, _, err := resolveProfile(configPath, opts.DiffProfile, opts.Variables)
+	if err != nil {
+		return err
+	}
+
+	diff := textdiff.Unified(profileLabel(configPath, opts.Profile), profileLabel(configPath, opts.DiffProfile), from, to, 3)
+	if diff == "" {
+		fmt.Fprintf(os.Stderr, "✅ %s 与 %s 的解析结果相同\n", profileName(opts.Profile), profileName(opts.DiffProfile))
+		return nil
+	}
+
+	printDiff(diff)
+	return nil
+}
+
+// printDiff 着色输出 unified diff
+func printDiff(diff string) {
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	hunkStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#4ECDC4"))
+	deleteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B"))
+	insertStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#2ECC71"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			line = headerStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			line = hunkStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = deleteStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			line = insertStyle.Render(line)
+		}
+		fmt.Println(line)
+	}
+}
+
+// resolveProfile 按指定 profile 加载配置并输出解析结果
+func resolveProfile(configPath, profile string, vars map[string]string) (string, []config.UnresolvedRef, error) {
+	cfg, err := loadConfig(configPath, profile, vars)
+	if err != nil {
+		return "", nil, fmt.Errorf("❌ 加载配置失败: %v", err)
+	}
+	out, unresolved, err := renderResolved(cfg, false)
+	if err != nil {
+		return "", nil, fmt.Errorf("❌ 解析配置失败: %v", err)
+	}
+	return out, unresolved, nil
+}
+
+// renderResolved 将变量替换后的配置编码为 YAML
+func renderResolved(cfg *config.Config, explain bool) (string, []config.UnresolvedRef, error) {
+	node, unresolved, err := cfg.ResolvedNode(explain)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return "", nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), unresolved, nil
+}
+
+// printUnresolved 在 stderr 提示未解析的变量引用（不影响 stdout 输出）
+func printUnresolved(unresolved []config.UnresolvedRef) {
+	if len(unresolved) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n⚠️  [WARN] %d 处变量引用未解析（既未在 variables/profile/--var 中定义，也不存在同名环境变量）:\n", len(unresolved))
+	for _, ref := range unresolved {
+		fmt.Fprintf(os.Stderr, "   %s: %s\n", ref.Path, ref.Ref)
+	}
+}
+
+// profileLabel 返回 diff 标题中的文件标签
+func profileLabel(configPath, profile string) string {
+	return fmt.Sprintf("%s (%s)", configPath, profileName(profile))
+}
+
+// profileName 返回 profile 显示名称
+func profileName(profile string) string {
+	if profile == "" {
+		return "默认"
+	}
+	return "profile: " + profile
+}
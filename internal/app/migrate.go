@@ -0,0 +1,79 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/textdiff"
+)
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	ConfigFile string // 配置文件路径
+	DryRun     bool   // 仅显示差异，不写入
+	Yes        bool   // 跳过确认直接写入
+}
+
+// RunMigrate 将配置文件迁移到当前版本，写入前显示差异并确认
+func RunMigrate(opts MigrateOptions) error {
+	configPath, err := resolveConfigPath(opts.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("❌ 读取配置文件失败: %v", err)
+	}
+
+	result, err := config.Migrate(data)
+	if err != nil {
+		return fmt.Errorf("❌ 迁移失败: %v", err)
+	}
+
+	if !result.Changed() {
+		fmt.Printf("✅ 配置已是最新版本 %s，无需迁移\n", config.CurrentVersion)
+		return nil
+	}
+
+	fmt.Printf("🔄 迁移配置文件: %s (%s → %s)\n", configPath, result.From, result.To)
+	for _, change := range result.Changes {
+		fmt.Printf("   • %s\n", change)
+	}
+	fmt.Println()
+
+	printDiff(textdiff.Unified(configPath, configPath+" (迁移后)", string(data), string(result.Output), 3))
+	fmt.Println()
+
+	if opts.DryRun {
+		fmt.Println("ℹ️  [INFO] --dry-run 模式，未写入文件")
+		return nil
+	}
+
+	if !opts.Yes && !confirm("是否写入迁移结果?") {
+		fmt.Println("⏭️  已取消，配置文件未修改")
+		return nil
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return fmt.Errorf("❌ 读取文件信息失败: %v", err)
+	}
+	if err := os.WriteFile(configPath, result.Output, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("❌ 写入配置文件失败: %v", err)
+	}
+
+	fmt.Printf("✅ 已迁移到版本 %s: %s\n", result.To, configPath)
+	return nil
+}
+
+// confirm 在终端询问是否继续（默认否）
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N]: ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/xiaolfeng/builder-cli/internal/pipeline"
+)
+
+// printRunSummary 输出运行摘要（构建的镜像、镜像 ID、摘要与标签）
+func printRunSummary(summary pipeline.Summary) {
+	if summary.ManifestErr != nil {
+		fmt.Printf("\n⚠️  [WARN] %v\n", summary.ManifestErr)
+	}
+	if len(summary.Images) == 0 {
+		return
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#4ECDC4"))
+	nameStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFE66D"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	fmt.Println()
+	fmt.Println(titleStyle.Render("📦 构建镜像"))
+	for _, img := range summary.Images {
+		fmt.Printf("\n  %s  %s\n", nameStyle.Render(img.Images[0]), mutedStyle.Render(img.Task))
+		for _, name := range img.Images[1:] {
+			fmt.Printf("    🏷️  %s\n", name)
+		}
+
+		switch {
+		case img.Error != "":
+			fmt.Printf("    ⚠️  %s\n", img.Error)
+		case img.ID != "":
+			fmt.Printf("    ID: %s\n", shortImageID(img.ID))
+		case len(img.Exports) > 0:
+			fmt.Printf("    ID: %s\n", mutedStyle.Render("已导出到文件，未加载到本地"))
+		case len(img.Platforms) > 0:
+			fmt.Printf("    ID: %s\n", mutedStyle.Render("多平台镜像未加载到本地"))
+		}
+		if digest := img.Digest(); digest != "" {
+			fmt.Printf("    Digest: %s\n", digest)
+		}
+		if len(img.Platforms) > 0 {
+			fmt.Printf("    Platforms: %s\n", strings.Join(img.Platforms, ", "))
+		}
+		for _, x := range img.Exports {
+			if x.Platform != "" {
+				fmt.Printf("    💾 %s (%s): %s\n", x.Platform, x.Format, x.Path)
+			} else {
+				fmt.Printf("    💾 %s: %s\n", x.Format, x.Path)
+			}
+		}
+		if img.Pushed {
+			fmt.Println("    ✅ 已在构建阶段推送")
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(img.Labels)) {
+			fmt.Printf("    %s %s\n", mutedStyle.Render(key+":"), img.Labels[key])
+		}
+	}
+
+	if summary.ManifestPath != "" {
+		fmt.Printf("\n📝 镜像清单: %s\n", summary.ManifestPath)
+	}
+}
+
+// shortImageID 返回镜像 ID 的前 12 位（去除 sha256: 前缀）
+func shortImageID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
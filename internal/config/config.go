@@ -1,16 +1,77 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // Config 根配置结构
 type Config struct {
-	Version    string              `yaml:"version"`
-	Project    ProjectConfig       `yaml:"project"`
-	Variables  map[string]string   `yaml:"variables"`
-	Registries map[string]Registry `yaml:"registries"`
-	Servers    map[string]Server   `yaml:"servers"`
-	Pipeline   []Stage             `yaml:"pipeline"`
-	Hooks      *Hooks              `yaml:"hooks,omitempty"`
+	Version      string              `yaml:"version"`
+	Project      ProjectConfig       `yaml:"project"`
+	Variables    map[string]string   `yaml:"variables"`
+	Profiles     map[string]Profile  `yaml:"profiles,omitempty"`
+	Registries   map[string]Registry `yaml:"registries"`
+	Servers      map[string]Server   `yaml:"servers"`
+	ServerGroups map[string][]string `yaml:"server_groups,omitempty"` // 服务器组（组名 → 服务器名称），SSH 任务通过 server_group 引用
+	Inventory    string              `yaml:"inventory,omitempty"`     // inventory 文件（YAML 或 INI），其中的服务器与服务器组合并到配置中
+	Pipeline     []Stage             `yaml:"pipeline"`
+	Hooks        *Hooks              `yaml:"hooks,omitempty"`
+
+	source    *Source          // 配置源信息（由 Loader 填充，用于定位错误位置）
+	resolver  *Resolver        // 变量解析器（由 Loader 填充，记录变量来源）
+	profile   string           // 激活的 profile
+	warnings  ValidationErrors // 加载时产生的警告（如过时的配置版本）
+	issues    ValidationErrors // 加载时发现的错误（如 ssh_config_host 无法解析），由 Validator 报告
+	only      []string         // 仅部署到这些服务器（--server 等），为空时不限制
+	inventory *inventory       // 加载的 inventory 文件（用于定位校验错误）
+}
+
+// Source 返回配置源信息（未通过 Loader 加载时为 nil）
+func (c *Config) Source() *Source {
+	return c.source
+}
+
+// Dir 返回配置文件所在目录（未通过 Loader 加载时为当前目录）
+func (c *Config) Dir() string {
+	if c.source == nil || c.source.File == "" {
+		return "."
+	}
+	return filepath.Dir(c.source.File)
+}
+
+// Warnings 返回加载时产生的警告
+func (c *Config) Warnings() ValidationErrors {
+	return c.warnings
+}
+
+// Resolver 返回变量解析器（未通过 Loader 加载时为 nil）
+func (c *Config) Resolver() *Resolver {
+	return c.resolver
+}
+
+// Profile 返回激活的 profile 名称（未激活时为空）
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// ProfileNames 返回所有 profile 名称（已排序）
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile 环境配置，激活后其变量覆盖全局 variables
+type Profile struct {
+	Variables map[string]string `yaml:"variables"`
 }
 
 // ProjectConfig 项目基本信息
@@ -21,24 +82,222 @@ type ProjectConfig struct {
 
 // Registry Docker Registry 配置
 type Registry struct {
-	URL      string `yaml:"url"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	URL              string `yaml:"url"`
+	Username         string `yaml:"username"`
+	Password         string `yaml:"password"`
+	UseDockerConfig  bool   `yaml:"use_docker_config,omitempty"` // 复用 ~/.docker/config.json 中已有的登录，不执行 docker login
+	CredentialHelper string `yaml:"credential_helper,omitempty"` // 通过 docker-credential-<helper> 获取凭据后登录
+	LogoutAfter      bool   `yaml:"logout_after,omitempty"`      // 推送任务结束后执行 docker logout
 }
 
 // Server SSH 服务器配置
 type Server struct {
-	Host     string     `yaml:"host"`
-	Port     int        `yaml:"port"`
-	Username string     `yaml:"username"`
-	Auth     ServerAuth `yaml:"auth"`
+	Host          string            `yaml:"host"`
+	Port          int               `yaml:"port"`
+	Username      string            `yaml:"username"`
+	Auth          ServerAuth        `yaml:"auth"`
+	HostKeyPolicy string            `yaml:"host_key_policy,omitempty"` // strict（默认）| tofu | insecure
+	KnownHosts    string            `yaml:"known_hosts,omitempty"`     // known_hosts 文件（默认 ~/.ssh/known_hosts）
+	HostKey       string            `yaml:"host_key,omitempty"`        // 固定的主机公钥（SHA256:... 指纹或 known_hosts 格式的公钥）
+	SSHConfigHost string            `yaml:"ssh_config_host,omitempty"` // 从 ssh 配置读取该 Host 的连接参数，显式配置的字段优先
+	SSHConfigFile string            `yaml:"ssh_config_file,omitempty"` // ssh 配置文件（默认 ~/.ssh/config）
+	Jump          string            `yaml:"jump,omitempty"`            // 跳板机（servers 中的名称），跳板机本身也可设置 jump
+	Tags          []string          `yaml:"tags,omitempty"`            // 标签，用于 server_tag 与 --server-tag 选择服务器
+	Groups        []string          `yaml:"groups,omitempty"`          // 所属的服务器组（与 server_groups 中列出的成员合并）
+	Vars          map[string]string `yaml:"vars,omitempty"`            // 服务器变量，SSH 命令中以 ${server.<名称>} 引用
+	ProxyJump     string            `yaml:"-"`                         // ssh 配置中的 ProxyJump
+	ProxyHops     []Server          `yaml:"-"`                         // 由 ProxyJump 解析出的跳板机（按连接顺序）
+}
+
+// Route 返回连接服务器需要依次经过的跳板机（不含服务器本身）
+// jump 优先于 ssh 配置中的 ProxyJump；链中最外层的服务器可再经过其 ProxyJump
+func (c *Config) Route(name string) ([]Server, error) {
+	srv, ok := c.Servers[name]
+	if !ok {
+		return nil, fmt.Errorf("服务器不存在: %s", name)
+	}
+
+	var route []Server
+	visited := []string{name}
+	for srv.Jump != "" {
+		if slices.Contains(visited, srv.Jump) {
+			return nil, fmt.Errorf("跳板机形成循环: %s → %s", strings.Join(visited, " → "), srv.Jump)
+		}
+		visited = append(visited, srv.Jump)
+		next, ok := c.Servers[srv.Jump]
+		if !ok {
+			return nil, fmt.Errorf("跳板机不存在: %s", srv.Jump)
+		}
+		route = append([]Server{next}, route...)
+		srv = next
+	}
+	return append(slices.Clone(srv.ProxyHops), route...), nil
+}
+
+// TaskServers 返回任务连接的服务器（server/servers 在前，再依次加入 server_group 的成员与带有 server_tag 的服务器，已去重）
+// 通过 RestrictServers 限制了目标服务器时只返回其中的服务器
+func (c *Config) TaskServers(settings TaskSettings) []string {
+	var names []string
+	if targeted, ok := settings.(ServerTargeted); ok {
+		names = append(names, targeted.TargetServers()...)
+	}
+	if grouped, ok := settings.(GroupTargeted); ok && grouped.TargetGroup() != "" {
+		names = append(names, c.GroupMembers(grouped.TargetGroup())...)
+	}
+	if tagged, ok := settings.(TagTargeted); ok && tagged.TargetTag() != "" {
+		names = append(names, c.TaggedServers(tagged.TargetTag())...)
+	}
+
+	var result []string
+	for _, name := range names {
+		if name == "" || slices.Contains(result, name) {
+			continue
+		}
+		if len(c.only) > 0 && !slices.Contains(c.only, name) {
+			continue
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+// RestrictServers 限制远程任务只连接指定的服务器（如 --server），多服务器任务跳过其他服务器
+func (c *Config) RestrictServers(names []string) {
+	c.only = names
+}
+
+// GroupMembers 返回服务器组的成员：server_groups 中列出的服务器在前，再按名称加入声明了 groups 的服务器
+func (c *Config) GroupMembers(group string) []string {
+	members := slices.Clone(c.ServerGroups[group])
+	for _, name := range c.ServerNames() {
+		if slices.Contains(c.Servers[name].Groups, group) && !slices.Contains(members, name) {
+			members = append(members, name)
+		}
+	}
+	return members
+}
+
+// HasGroup 检查服务器组是否存在（在 server_groups 中定义，或有服务器声明属于该组）
+func (c *Config) HasGroup(group string) bool {
+	return slices.Contains(c.GroupNames(), group)
+}
+
+// GroupNames 返回所有服务器组名称（已排序）
+func (c *Config) GroupNames() []string {
+	var names []string
+	for name := range c.ServerGroups {
+		names = append(names, name)
+	}
+	for _, srv := range c.Servers {
+		names = append(names, srv.Groups...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// TaggedServers 返回带有标签的服务器（按名称排序）
+func (c *Config) TaggedServers(tag string) []string {
+	var names []string
+	for _, name := range c.ServerNames() {
+		if slices.Contains(c.Servers[name].Tags, tag) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// HasTag 检查是否有服务器带有标签
+func (c *Config) HasTag(tag string) bool {
+	return len(c.TaggedServers(tag)) > 0
+}
+
+// TagNames 返回所有服务器标签（已排序）
+func (c *Config) TagNames() []string {
+	var tags []string
+	for _, srv := range c.Servers {
+		tags = append(tags, srv.Tags...)
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// ServerNames 返回所有服务器名称（已排序）
+func (c *Config) ServerNames() []string {
+	names := make([]string, 0, len(c.Servers))
+	for name := range c.Servers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ServerVariables 返回服务器变量（不含 server. 前缀）：内置的 name、host、port、username 与 vars 中定义的变量
+func (c *Config) ServerVariables(name string) map[string]string {
+	srv := c.Servers[name]
+	vars := make(map[string]string, len(srv.Vars)+len(BuiltinServerVariables))
+	for k, v := range srv.Vars {
+		vars[k] = v
+	}
+	port := srv.Port
+	if port == 0 {
+		port = 22
+	}
+	vars["name"] = name
+	vars["host"] = srv.Host
+	vars["port"] = strconv.Itoa(port)
+	vars["username"] = srv.Username
+	return vars
+}
+
+// BuiltinServerVariables 内置的服务器变量，vars 中不能重新定义
+var BuiltinServerVariables = []string{"name", "host", "port", "username"}
+
+// 主机密钥校验策略
+const (
+	HostKeyStrict   = "strict"   // 必须在 known_hosts 或 host_key 中找到匹配的公钥
+	HostKeyTOFU     = "tofu"     // 首次连接时记录公钥到 known_hosts，之后严格校验
+	HostKeyInsecure = "insecure" // 不校验主机密钥（存在中间人攻击风险）
+)
+
+// HostKeyPolicies 支持的主机密钥校验策略
+var HostKeyPolicies = []string{HostKeyStrict, HostKeyTOFU, HostKeyInsecure}
+
+// Policy 返回主机密钥校验策略（未设置时为 strict）
+func (s Server) Policy() string {
+	if s.HostKeyPolicy == "" {
+		return HostKeyStrict
+	}
+	return s.HostKeyPolicy
 }
 
 // ServerAuth SSH 认证配置
+// 设置 methods 时按顺序依次尝试其中的认证方式（此时不能再设置 type）
 type ServerAuth struct {
-	Type     string `yaml:"type"` // "password" | "key"
-	Password string `yaml:"password,omitempty"`
-	KeyPath  string `yaml:"key_path,omitempty"`
+	Type        string       `yaml:"type,omitempty"` // password | key | agent | keyboard-interactive
+	Password    string       `yaml:"password,omitempty"`
+	KeyPath     string       `yaml:"key_path,omitempty"`
+	Passphrase  string       `yaml:"passphrase,omitempty"`  // 加密私钥的密码短语，未设置时在 TUI 中输入
+	Certificate string       `yaml:"certificate,omitempty"` // OpenSSH 证书（默认使用存在的 <key_path>-cert.pub）
+	Methods     []ServerAuth `yaml:"methods,omitempty"`     // 依次尝试的认证方式
+}
+
+// SSH 认证类型
+const (
+	AuthPassword            = "password"             // 密码
+	AuthKey                 = "key"                  // 私钥文件（可加密，可附带证书）
+	AuthAgent               = "agent"                // ssh-agent（SSH_AUTH_SOCK）
+	AuthKeyboardInteractive = "keyboard-interactive" // 服务器提问，password 或 TUI 中输入作答
+)
+
+// AuthTypes 支持的 SSH 认证类型
+var AuthTypes = []string{AuthPassword, AuthKey, AuthAgent, AuthKeyboardInteractive}
+
+// Chain 返回按顺序尝试的认证方式
+func (a ServerAuth) Chain() []ServerAuth {
+	if len(a.Methods) > 0 {
+		return a.Methods
+	}
+	return []ServerAuth{a}
 }
 
 // Stage 流水线阶段
@@ -52,76 +311,13 @@ type Stage struct {
 // Task 任务配置
 type Task struct {
 	Name   string     `yaml:"name"`
-	Type   string     `yaml:"type"` // "maven" | "docker-build" | "docker-push" | "ssh"
+	Type   string     `yaml:"type"` // 任务类型，由执行器注册表提供（maven、docker-build、ssh 等）
 	Config TaskConfig `yaml:"config"`
 }
 
-// TaskConfig 任务具体配置
-type TaskConfig struct {
-	// 通用配置
-	WorkingDir string `yaml:"working_dir,omitempty"`
-	Timeout    int    `yaml:"timeout,omitempty"` // 超时时间（秒）
-
-	// Maven 配置
-	Command string `yaml:"command,omitempty"`
-	Script  string `yaml:"script,omitempty"`
-
-	// Docker Build 配置
-	Dockerfile        string            `yaml:"dockerfile,omitempty"`
-	Context           string            `yaml:"context,omitempty"`
-	ImageName         string            `yaml:"image_name,omitempty"`
-	Tag               string            `yaml:"tag,omitempty"`
-	BuildArgs         map[string]string `yaml:"build_args,omitempty"`
-	Platforms         []string          `yaml:"platforms,omitempty"`            // 多平台构建，如 ["linux/amd64", "linux/arm64"]
-	ForceRefresh      bool              `yaml:"force_refresh,omitempty"`        // Docker 构建日志强制降级刷新（避免刷屏）
-	PushOnBuild       *bool             `yaml:"push_on_build,omitempty"`        // 多平台构建时是否自动推送 (默认 true)
-	PushLatestOnBuild bool              `yaml:"push_latest_on_build,omitempty"` // 多平台构建时是否同时推送 latest 标签
-	AutoScan          *AutoScanConfig   `yaml:"auto_scan,omitempty"`
-
-	// Docker Push 配置
-	Registry   string   `yaml:"registry,omitempty"`
-	Images     []string `yaml:"images,omitempty"`
-	Auto       bool     `yaml:"auto,omitempty"`
-	PushLatest bool     `yaml:"push_latest,omitempty"` // 同时推送 latest 标签
-
-	// SSH 配置
-	Server      string   `yaml:"server,omitempty"`
-	Commands    []string `yaml:"commands,omitempty"`
-	LocalScript string   `yaml:"local_script,omitempty"`
-
-	// Go Build 配置
-	GoCommand  string `yaml:"go_command,omitempty"`  // go 子命令: build/test/generate (默认 build)
-	GoOS       string `yaml:"goos,omitempty"`        // 目标操作系统
-	GoArch     string `yaml:"goarch,omitempty"`      // 目标架构
-	Output     string `yaml:"output,omitempty"`      // 输出文件路径 (-o)
-	LDFlags    string `yaml:"ldflags,omitempty"`     // 链接标志 (-ldflags)
-	BuildTags  string `yaml:"tags,omitempty"`        // 构建标签 (-tags)
-	CGOEnabled *bool  `yaml:"cgo_enabled,omitempty"` // CGO 开关 (指针区分未设置和 false)
-	GoPrivate  string `yaml:"goprivate,omitempty"`   // GOPRIVATE 环境变量
-	GoProxy    string `yaml:"goproxy,omitempty"`     // GOPROXY 环境变量
-	Race       bool   `yaml:"race,omitempty"`        // 竞态检测 (-race)
-	Trimpath   bool   `yaml:"trimpath,omitempty"`    // 移除路径 (-trimpath)
-	Mod        string `yaml:"mod,omitempty"`         // 模块模式 (-mod=vendor/readonly/mod)
-	Packages   string `yaml:"packages,omitempty"`    // 目标包 (默认 .)
-	GoVerbose  bool   `yaml:"go_verbose,omitempty"`  // 详细输出 (-v)
-}
-
-// GetTimeoutDuration 返回超时时间的 Duration 格式
-func (c TaskConfig) GetTimeoutDuration() time.Duration {
-	if c.Timeout <= 0 {
-		return 5 * time.Minute // 默认 5 分钟
-	}
-	return time.Duration(c.Timeout) * time.Second
-}
-
-// AutoScanConfig Dockerfile 自动扫描配置
-type AutoScanConfig struct {
-	Enabled     bool     `yaml:"enabled"`
-	Pattern     string   `yaml:"pattern"`
-	Exclude     []string `yaml:"exclude,omitempty"`
-	ImagePrefix string   `yaml:"image_prefix,omitempty"`
-	Tag         string   `yaml:"tag,omitempty"`
-	Platforms   []string `yaml:"platforms,omitempty"` // 多平台构建，如 ["linux/amd64", "linux/arm64"]
+// Settings 按任务类型解码任务配置
+func (t Task) Settings() (TaskSettings, error) {
+	return t.Config.Decode(t.Type)
 }
 
 // Hooks 钩子配置
@@ -130,13 +326,3 @@ type Hooks struct {
 	PostBuild []string `yaml:"post_build,omitempty"`
 	OnFailure []string `yaml:"on_failure,omitempty"`
 }
-
-// TaskType 任务类型常量
-const (
-	TaskTypeMaven       = "maven"
-	TaskTypeDockerBuild = "docker-build"
-	TaskTypeDockerPush  = "docker-push"
-	TaskTypeSSH         = "ssh"
-	TaskTypeGoBuild     = "go-build"
-	TaskTypeShell       = "shell"
-)
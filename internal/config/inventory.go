@@ -0,0 +1,364 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// inventory 从 inventory 文件加载的服务器与服务器组，用于将校验错误定位到 inventory 文件
+type inventory struct {
+	source  *Source        // inventory 文件源信息（INI 格式时无节点树）
+	lines   map[string]int // INI 格式中配置项所在行（键为 servers.<name>、server_groups.<name>）
+	servers map[string]bool
+	groups  map[string]bool
+}
+
+// inventoryFile YAML 格式的 inventory 文件，与配置文件中的 servers / server_groups 格式相同
+type inventoryFile struct {
+	Servers      map[string]Server   `yaml:"servers"`
+	ServerGroups map[string][]string `yaml:"server_groups,omitempty"`
+}
+
+// loadInventory 加载 inventory 文件，将其中的服务器与服务器组合并到配置中
+// 路径支持变量引用，相对路径相对于配置文件所在目录；.yaml / .yml 按 YAML 解析，其他按 INI 解析
+func loadInventory(cfg *Config) {
+	path := expandHomePath(cfg.resolver.Expand(cfg.Inventory))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cfg.Dir(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		cfg.issues = append(cfg.issues, cfg.source.newIssue(SeverityError, "inventory", fmt.Sprintf("读取 inventory 文件失败: %v", err)))
+		return
+	}
+
+	var (
+		inv  *inventory
+		file inventoryFile
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		inv, file, err = parseYAMLInventory(path, data)
+	default:
+		inv, file, err = parseINIInventory(path, data)
+	}
+	if err != nil {
+		cfg.issues = append(cfg.issues, cfg.source.newIssue(SeverityError, "inventory", fmt.Sprintf("解析 inventory 文件失败: %v", err)))
+		return
+	}
+	cfg.inventory = inv
+
+	// 同名的服务器或服务器组不合并，避免 inventory 悄悄覆盖配置文件中的定义
+	for name, srv := range file.Servers {
+		if _, exists := cfg.Servers[name]; exists {
+			cfg.issues = append(cfg.issues, inv.newIssue(SeverityError, "servers."+name, fmt.Sprintf("服务器在配置文件与 inventory 中重复定义: %s", name)))
+			continue
+		}
+		if cfg.Servers == nil {
+			cfg.Servers = make(map[string]Server)
+		}
+		cfg.Servers[name] = srv
+		inv.servers[name] = true
+	}
+	for name, members := range file.ServerGroups {
+		if _, exists := cfg.ServerGroups[name]; exists {
+			cfg.issues = append(cfg.issues, inv.newIssue(SeverityError, "server_groups."+name, fmt.Sprintf("服务器组在配置文件与 inventory 中重复定义: %s", name)))
+			continue
+		}
+		if cfg.ServerGroups == nil {
+			cfg.ServerGroups = make(map[string][]string)
+		}
+		cfg.ServerGroups[name] = members
+		inv.groups[name] = true
+	}
+}
+
+// parseYAMLInventory 解析 YAML 格式的 inventory 文件（不允许未知字段）
+func parseYAMLInventory(path string, data []byte) (*inventory, inventoryFile, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, inventoryFile{}, err
+	}
+
+	var file inventoryFile
+	if len(doc.Content) > 0 {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return nil, inventoryFile{}, err
+		}
+	}
+	return newInventory(newSource(path, data, &doc)), file, nil
+}
+
+// iniHost INI inventory 中的一台服务器（可出现在多个组中）
+type iniHost struct {
+	fields map[string]string
+	groups []string
+}
+
+// parseINIInventory 解析 INI 格式的 inventory 文件
+//
+//	[web]                                   # 服务器组
+//	web-1 host=10.0.0.1 tags=blue,cn region=cn-east
+//	[web:vars]                              # 组内服务器的默认值（[all:vars] 对所有服务器生效）
+//	user=deploy
+//
+// 服务器行中的 host、port、user、auth、key_path 等键对应服务器字段，其他键作为服务器变量（vars）
+func parseINIInventory(path string, data []byte) (*inventory, inventoryFile, error) {
+	inv := newInventory(newSource(path, data, nil))
+	file := inventoryFile{Servers: make(map[string]Server), ServerGroups: make(map[string][]string)}
+
+	hosts := make(map[string]*iniHost)
+	var order []string
+	groupVars := make(map[string]map[string]string)
+
+	group, varsSection := "", false
+	for i, raw := range inv.source.Lines {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, file, fmt.Errorf("第 %d 行: 无效的分组: %s", lineNo, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			group, varsSection = strings.CutSuffix(name, ":vars")
+			if group == "" {
+				return nil, file, fmt.Errorf("第 %d 行: 分组名称不能为空", lineNo)
+			}
+			if varsSection {
+				if groupVars[group] == nil {
+					groupVars[group] = make(map[string]string)
+				}
+			} else if group != "all" && group != "ungrouped" {
+				if _, ok := file.ServerGroups[group]; !ok {
+					file.ServerGroups[group] = []string{}
+					inv.lines["server_groups."+group] = lineNo
+				}
+			}
+			continue
+		}
+
+		if varsSection {
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, file, fmt.Errorf("第 %d 行: 无效的变量，应为 key=value: %s", lineNo, line)
+			}
+			groupVars[group][strings.TrimSpace(key)] = unquoteINI(strings.TrimSpace(value))
+			continue
+		}
+
+		fields, err := splitINIFields(line)
+		if err != nil {
+			return nil, file, fmt.Errorf("第 %d 行: %w", lineNo, err)
+		}
+		name := fields[0]
+		host, ok := hosts[name]
+		if !ok {
+			host = &iniHost{fields: make(map[string]string)}
+			hosts[name] = host
+			order = append(order, name)
+			inv.lines["servers."+name] = lineNo
+		}
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || key == "" {
+				return nil, file, fmt.Errorf("第 %d 行: 无效的主机变量，应为 key=value: %s", lineNo, field)
+			}
+			host.fields[key] = unquoteINI(value)
+		}
+		if group != "" && group != "all" && group != "ungrouped" {
+			file.ServerGroups[group] = append(file.ServerGroups[group], name)
+			host.groups = append(host.groups, group)
+		}
+	}
+
+	for _, name := range order {
+		host := hosts[name]
+
+		// 优先级: 服务器行 > [<组>:vars] > [all:vars]
+		fields := make(map[string]string)
+		for _, g := range append([]string{"all"}, host.groups...) {
+			for k, v := range groupVars[g] {
+				fields[k] = v
+			}
+		}
+		for k, v := range host.fields {
+			fields[k] = v
+		}
+
+		srv := Server{Port: 22}
+		for key, value := range fields {
+			if err := srv.setINIField(key, value); err != nil {
+				return nil, file, fmt.Errorf("第 %d 行: 服务器 %s: %w", inv.lines["servers."+name], name, err)
+			}
+		}
+		file.Servers[name] = srv
+	}
+	return inv, file, nil
+}
+
+// setINIField 设置 INI inventory 中的服务器字段，未知的键作为服务器变量
+func (s *Server) setINIField(key, value string) error {
+	switch key {
+	case "host":
+		s.Host = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("无效的端口号: %s", value)
+		}
+		s.Port = port
+	case "user", "username":
+		s.Username = value
+	case "auth":
+		s.Auth.Type = value
+	case "key_path":
+		s.Auth.KeyPath = value
+	case "password":
+		s.Auth.Password = value
+	case "passphrase":
+		s.Auth.Passphrase = value
+	case "certificate":
+		s.Auth.Certificate = value
+	case "jump":
+		s.Jump = value
+	case "host_key_policy":
+		s.HostKeyPolicy = value
+	case "known_hosts":
+		s.KnownHosts = value
+	case "host_key":
+		s.HostKey = value
+	case "ssh_config_host":
+		s.SSHConfigHost = value
+	case "ssh_config_file":
+		s.SSHConfigFile = value
+	case "tags":
+		s.Tags = nil
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				s.Tags = append(s.Tags, tag)
+			}
+		}
+	default:
+		if s.Vars == nil {
+			s.Vars = make(map[string]string)
+		}
+		s.Vars[key] = value
+	}
+	return nil
+}
+
+// splitINIFields 按空白拆分服务器行，引号内的空白不拆分
+func splitINIFields(line string) ([]string, error) {
+	var (
+		fields []string
+		field  strings.Builder
+		quote  rune
+	)
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			field.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			field.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("引号未闭合: %s", line)
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// unquoteINI 去除值两侧成对的引号
+func unquoteINI(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// newInventory 创建 inventory 源信息
+func newInventory(source *Source) *inventory {
+	return &inventory{
+		source:  source,
+		lines:   make(map[string]int),
+		servers: make(map[string]bool),
+		groups:  make(map[string]bool),
+	}
+}
+
+// owns 检查逻辑路径是否指向 inventory 中定义的服务器或服务器组
+func (inv *inventory) owns(field string) bool {
+	segs := splitPath(field)
+	if len(segs) < 2 {
+		return false
+	}
+	switch segs[0].key {
+	case "servers":
+		return inv.servers[segs[1].key]
+	case "server_groups":
+		return inv.groups[segs[1].key]
+	}
+	return false
+}
+
+// newIssue 创建定位到 inventory 文件的验证结果
+// YAML 格式按逻辑路径定位；INI 格式定位到服务器或服务器组所在行
+func (inv *inventory) newIssue(severity, field, message string) ValidationError {
+	if inv.source.root != nil {
+		return inv.source.newIssue(severity, field, message)
+	}
+
+	issue := ValidationError{Field: field, Message: message, Severity: severity, File: inv.source.File}
+	if segs := splitPath(field); len(segs) >= 2 {
+		if line, ok := inv.lines[segs[0].key+"."+segs[1].key]; ok {
+			issue.Line, issue.Column = line, 1
+		}
+	}
+	return issue
+}
+
+// newIssue 创建验证结果，inventory 中定义的服务器与服务器组定位到 inventory 文件
+func (c *Config) newIssue(severity, field, message string) ValidationError {
+	if c.inventory != nil && c.inventory.owns(field) {
+		return c.inventory.newIssue(severity, field, message)
+	}
+	return c.source.newIssue(severity, field, message)
+}
+
+// SourceOf 返回文件对应的源信息（配置文件或 inventory 文件），用于显示出错位置附近的代码
+func (c *Config) SourceOf(file string) *Source {
+	if c.inventory != nil && c.inventory.source.File == file {
+		return c.inventory.source
+	}
+	if c.source != nil && c.source.File == file {
+		return c.source
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package config
 import (
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -12,6 +11,8 @@ import (
 // Loader 配置加载器
 type Loader struct {
 	configPath string
+	profile    string            // 激活的 profile（可选）
+	overrides  map[string]string // 命令行 --var 覆盖的变量
 }
 
 // NewLoader 创建配置加载器
@@ -19,6 +20,16 @@ func NewLoader(configPath string) *Loader {
 	return &Loader{configPath: configPath}
 }
 
+// SetProfile 设置激活的 profile，其 variables 覆盖全局 variables
+func (l *Loader) SetProfile(profile string) {
+	l.profile = profile
+}
+
+// SetVariables 设置命令行覆盖的变量（优先级最高）
+func (l *Loader) SetVariables(vars map[string]string) {
+	l.overrides = vars
+}
+
 // Load 加载并解析配置文件
 func (l *Loader) Load() (*Config, error) {
 	data, err := os.ReadFile(l.configPath)
@@ -26,158 +37,105 @@ func (l *Loader) Load() (*Config, error) {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	// 先解析为节点树，保留行列信息用于错误定位
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
+	var cfg Config
+	if len(doc.Content) > 0 {
+		if err := doc.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %w", err)
+		}
+	}
+	cfg.source = newSource(l.configPath, data, &doc)
+
+	// 检查配置版本
+	if err := checkVersion(&cfg); err != nil {
+		return nil, err
+	}
+
+	if l.profile != "" {
+		if _, ok := cfg.Profiles[l.profile]; !ok {
+			return nil, fmt.Errorf("profile 不存在: %s (可用: %s)", l.profile, strings.Join(cfg.ProfileNames(), ", "))
+		}
+	}
+	cfg.profile = l.profile
+
 	// 替换变量
+	cfg.resolver = newResolver(&cfg, l.configPath, l.profile, l.overrides)
+
+	// 合并 inventory 文件中的服务器与服务器组（与配置文件中的服务器一同替换变量）
+	if cfg.Inventory != "" {
+		loadInventory(&cfg)
+	}
 	l.replaceVariables(&cfg)
 
+	// 从 ssh 配置补全服务器连接参数
+	resolveSSHConfig(&cfg)
+
 	return &cfg, nil
 }
 
 // replaceVariables 替换配置中的变量引用
 func (l *Loader) replaceVariables(cfg *Config) {
-	// 构建变量映射（包含环境变量）
-	vars := make(map[string]string)
-	for k, v := range cfg.Variables {
-		vars[k] = v
-	}
-
-	// 先对变量值本身进行展开（支持变量间引用）
-	// 多次迭代以支持嵌套引用
-	for i := 0; i < 10; i++ {
-		changed := false
-		for k, v := range vars {
-			expanded := l.expandVars(v, vars)
-			if expanded != v {
-				vars[k] = expanded
-				changed = true
-			}
-		}
-		if !changed {
-			break
-		}
-	}
-
 	// 递归替换所有字符串字段
-	l.replaceInRegistries(cfg.Registries, vars)
-	l.replaceInServers(cfg.Servers, vars)
-	l.replaceInPipeline(cfg.Pipeline, vars)
+	l.replaceInRegistries(cfg.Registries, cfg.resolver)
+	l.replaceInServers(cfg.Servers, cfg.resolver)
+	l.replaceInPipeline(cfg.Pipeline, cfg.resolver)
 }
 
 // replaceInRegistries 替换 Registry 配置中的变量
-func (l *Loader) replaceInRegistries(registries map[string]Registry, vars map[string]string) {
+func (l *Loader) replaceInRegistries(registries map[string]Registry, r *Resolver) {
 	for name, reg := range registries {
-		reg.URL = l.expandVars(reg.URL, vars)
-		reg.Username = l.expandVars(reg.Username, vars)
-		reg.Password = l.expandVars(reg.Password, vars)
+		reg.URL = r.Expand(reg.URL)
+		reg.Username = r.Expand(reg.Username)
+		reg.Password = r.Expand(reg.Password)
+		reg.CredentialHelper = r.Expand(reg.CredentialHelper)
 		registries[name] = reg
 	}
 }
 
 // replaceInServers 替换 Server 配置中的变量
-func (l *Loader) replaceInServers(servers map[string]Server, vars map[string]string) {
+func (l *Loader) replaceInServers(servers map[string]Server, r *Resolver) {
 	for name, srv := range servers {
-		srv.Host = l.expandVars(srv.Host, vars)
-		srv.Username = l.expandVars(srv.Username, vars)
-		srv.Auth.Password = l.expandVars(srv.Auth.Password, vars)
-		srv.Auth.KeyPath = l.expandVars(srv.Auth.KeyPath, vars)
+		srv.Host = r.Expand(srv.Host)
+		srv.Username = r.Expand(srv.Username)
+		expandAuth(&srv.Auth, r)
+		srv.HostKeyPolicy = r.Expand(srv.HostKeyPolicy)
+		srv.KnownHosts = r.Expand(srv.KnownHosts)
+		srv.HostKey = r.Expand(srv.HostKey)
+		srv.SSHConfigHost = r.Expand(srv.SSHConfigHost)
+		srv.SSHConfigFile = r.Expand(srv.SSHConfigFile)
+		srv.Jump = r.Expand(srv.Jump)
+		for k, v := range srv.Vars {
+			srv.Vars[k] = r.Expand(v)
+		}
 		servers[name] = srv
 	}
 }
 
-// replaceInPipeline 替换 Pipeline 配置中的变量
-func (l *Loader) replaceInPipeline(stages []Stage, vars map[string]string) {
-	for i := range stages {
-		for j := range stages[i].Tasks {
-			task := &stages[i].Tasks[j]
-			task.Config.WorkingDir = l.expandVars(task.Config.WorkingDir, vars)
-			task.Config.Command = l.expandVars(task.Config.Command, vars)
-			task.Config.Script = l.expandVars(task.Config.Script, vars)
-			task.Config.Dockerfile = l.expandVars(task.Config.Dockerfile, vars)
-			task.Config.Context = l.expandVars(task.Config.Context, vars)
-			task.Config.ImageName = l.expandVars(task.Config.ImageName, vars)
-			task.Config.Tag = l.expandVars(task.Config.Tag, vars)
-			task.Config.Registry = l.expandVars(task.Config.Registry, vars)
-			task.Config.LocalScript = l.expandVars(task.Config.LocalScript, vars)
-			task.Config.GoCommand = l.expandVars(task.Config.GoCommand, vars)
-			task.Config.GoOS = l.expandVars(task.Config.GoOS, vars)
-			task.Config.GoArch = l.expandVars(task.Config.GoArch, vars)
-			task.Config.Output = l.expandVars(task.Config.Output, vars)
-			task.Config.LDFlags = l.expandVars(task.Config.LDFlags, vars)
-			task.Config.BuildTags = l.expandVars(task.Config.BuildTags, vars)
-			task.Config.GoPrivate = l.expandVars(task.Config.GoPrivate, vars)
-			task.Config.GoProxy = l.expandVars(task.Config.GoProxy, vars)
-			task.Config.Mod = l.expandVars(task.Config.Mod, vars)
-			task.Config.Packages = l.expandVars(task.Config.Packages, vars)
-
-			// 替换 images 数组
-			for k := range task.Config.Images {
-				task.Config.Images[k] = l.expandVars(task.Config.Images[k], vars)
-			}
-
-			// 替换 commands 数组
-			for k := range task.Config.Commands {
-				task.Config.Commands[k] = l.expandVars(task.Config.Commands[k], vars)
-			}
-
-			// 替换 platforms 数组
-			for k := range task.Config.Platforms {
-				task.Config.Platforms[k] = l.expandVars(task.Config.Platforms[k], vars)
-			}
-
-			// 替换 build_args
-			for k, v := range task.Config.BuildArgs {
-				task.Config.BuildArgs[k] = l.expandVars(v, vars)
-			}
-
-			// 替换 auto_scan 配置
-			if task.Config.AutoScan != nil {
-				task.Config.AutoScan.Pattern = l.expandVars(task.Config.AutoScan.Pattern, vars)
-				task.Config.AutoScan.ImagePrefix = l.expandVars(task.Config.AutoScan.ImagePrefix, vars)
-				task.Config.AutoScan.Tag = l.expandVars(task.Config.AutoScan.Tag, vars)
-			}
-		}
+// expandAuth 替换 SSH 认证配置中的变量（含 methods 中的每种认证方式）
+func expandAuth(auth *ServerAuth, r *Resolver) {
+	auth.Type = r.Expand(auth.Type)
+	auth.Password = r.Expand(auth.Password)
+	auth.KeyPath = r.Expand(auth.KeyPath)
+	auth.Passphrase = r.Expand(auth.Passphrase)
+	auth.Certificate = r.Expand(auth.Certificate)
+	for i := range auth.Methods {
+		expandAuth(&auth.Methods[i], r)
 	}
 }
 
-// expandVars 展开字符串中的变量引用
-// 支持格式: ${VAR_NAME} 和 $VAR_NAME
-func (l *Loader) expandVars(s string, vars map[string]string) string {
-	if s == "" {
-		return s
-	}
-
-	// 匹配 ${VAR_NAME} 格式
-	re := regexp.MustCompile(`\$\{([^}]+)\}`)
-	result := re.ReplaceAllStringFunc(s, func(match string) string {
-		varName := match[2 : len(match)-1] // 提取变量名
-		if val, ok := vars[varName]; ok {
-			return val
-		}
-		// 尝试从环境变量获取
-		if val := os.Getenv(varName); val != "" {
-			return val
-		}
-		return match // 保持原样
-	})
-
-	// 匹配 $VAR_NAME 格式（仅字母数字下划线）
-	re2 := regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
-	result = re2.ReplaceAllStringFunc(result, func(match string) string {
-		varName := match[1:] // 提取变量名
-		if val, ok := vars[varName]; ok {
-			return val
-		}
-		if val := os.Getenv(varName); val != "" {
-			return val
+// replaceInPipeline 为任务配置设置变量展开函数
+// 任务配置按类型延迟解码，解码时由各类型的 Expand 展开变量
+func (l *Loader) replaceInPipeline(stages []Stage, r *Resolver) {
+	for i := range stages {
+		for j := range stages[i].Tasks {
+			stages[i].Tasks[j].Config.expand = r.Expand
 		}
-		return match
-	})
-
-	return result
+	}
 }
 
 // FindConfigFile 在当前目录及父目录中查找配置文件
@@ -0,0 +1,292 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/textdiff"
+	"gopkg.in/yaml.v3"
+)
+
+// Migration 配置格式迁移步骤，将 From 版本的节点树原地改写为 To 版本
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Apply       func(root *yaml.Node) []string // 返回具体变更说明
+}
+
+// migrations 按版本顺序登记的迁移步骤，格式变更时追加
+var migrations = []Migration{
+	{
+		From:        "1.0",
+		To:          "1.1",
+		Description: "docker 任务的 push_latest_on_build / push_latest 迁移为 tag_strategies",
+		Apply:       migrateTagStrategies,
+	},
+}
+
+// MigrationResult 迁移结果
+type MigrationResult struct {
+	From    string   // 迁移前版本
+	To      string   // 迁移后版本
+	Changes []string // 变更说明
+	Output  []byte   // 迁移后的配置内容
+}
+
+// Changed 返回配置内容是否有变化
+func (r *MigrationResult) Changed() bool {
+	return r.From != r.To || len(r.Changes) > 0
+}
+
+// Migrate 将配置迁移到当前版本，保留注释与原有格式
+func Migrate(data []byte) (*MigrationResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("配置文件为空或格式错误")
+	}
+	root := doc.Content[0]
+
+	// 未迁移前的规范化输出，用于把变更映射回原文
+	before, err := encodeNode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{From: legacyVersion}
+	versionNode := mappingValue(root, "version")
+	declared := versionNode != nil && versionNode.Value != ""
+	if declared {
+		result.From = versionNode.Value
+	}
+
+	switch {
+	case compareVersions(result.From, CurrentVersion) > 0:
+		return nil, fmt.Errorf("配置版本 %s 高于当前支持的最高版本 %s，请升级 xbuilder", result.From, CurrentVersion)
+	case result.From == CurrentVersion && declared:
+		result.To = CurrentVersion
+		result.Output = data
+		return result, nil
+	}
+
+	version := result.From
+	for _, m := range migrations {
+		if m.From != version {
+			continue
+		}
+		for _, change := range m.Apply(root) {
+			result.Changes = append(result.Changes, fmt.Sprintf("[%s → %s] %s", m.From, m.To, change))
+		}
+		version = m.To
+	}
+	if version != CurrentVersion {
+		return nil, fmt.Errorf("不支持从版本 %s 迁移 (支持: %s)", result.From, strings.Join(SupportedVersions, ", "))
+	}
+	result.To = version
+
+	// 更新或插入 version 字段
+	if !declared {
+		result.Changes = append(result.Changes, fmt.Sprintf("声明 version: %q", version))
+	}
+	if versionNode != nil {
+		versionNode.Value = version
+		versionNode.Tag = "!!str"
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: version},
+		}, root.Content...)
+	}
+
+	after, err := encodeNode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	result.Output = applyToOriginal(string(data), before, after)
+	return result, nil
+}
+
+// migrateTagStrategies 1.0 → 1.1: push_latest_on_build / push_latest 改为 tag_strategies: [latest]
+func migrateTagStrategies(root *yaml.Node) []string {
+	var changes []string
+	forEachTask(root, func(path string, task *yaml.Node) {
+		t := mappingValue(task, "type")
+		if t == nil || (t.Value != "docker-build" && t.Value != "docker-push") {
+			return
+		}
+		cfg := mappingValue(task, "config")
+		if cfg == nil || cfg.Kind != yaml.MappingNode {
+			return
+		}
+
+		latestKey := "push_latest"
+		if t.Value == "docker-build" {
+			latestKey = "push_latest_on_build"
+		}
+		latest := mappingValue(cfg, latestKey)
+		if latest == nil {
+			return
+		}
+		strategies := mappingValue(cfg, "tag_strategies")
+		switch {
+		case latest.Value != "true":
+			removeKey(cfg, latestKey)
+			changes = append(changes, fmt.Sprintf("%s.config: 移除 %s: %s", path, latestKey, latest.Value))
+		case strategies == nil:
+			// 原位替换为 tag_strategies: ["latest"]
+			renameKey(cfg, latestKey, "tag_strategies")
+			*latest = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: "latest"},
+			}}
+			changes = append(changes, fmt.Sprintf("%s.config: %s: true → tag_strategies: [latest]", path, latestKey))
+		default:
+			removeKey(cfg, latestKey)
+			for _, s := range strategies.Content {
+				if s.Value == "latest" {
+					changes = append(changes, fmt.Sprintf("%s.config: 移除 %s（tag_strategies 已包含 latest）", path, latestKey))
+					return
+				}
+			}
+			item := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: "latest"}
+			if n := len(strategies.Content); n > 0 {
+				item.Style = strategies.Content[n-1].Style
+			}
+			strategies.Content = append(strategies.Content, item)
+			changes = append(changes, fmt.Sprintf("%s.config: %s: true 合并到 tag_strategies", path, latestKey))
+		}
+	})
+	return changes
+}
+
+// renameKey 原位重命名映射节点中的键，键不存在时返回 false
+func renameKey(node *yaml.Node, from, to string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == from {
+			node.Content[i].Value = to
+			return true
+		}
+	}
+	return false
+}
+
+// removeKey 从映射节点中移除指定键，返回被移除的值节点
+func removeKey(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+// forEachTask 遍历流水线中的所有任务节点
+func forEachTask(root *yaml.Node, fn func(path string, task *yaml.Node)) {
+	pipeline := mappingValue(root, "pipeline")
+	if pipeline == nil || pipeline.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, stage := range pipeline.Content {
+		tasks := mappingValue(stage, "tasks")
+		if tasks == nil || tasks.Kind != yaml.SequenceNode {
+			continue
+		}
+		for j, task := range tasks.Content {
+			if task.Kind == yaml.MappingNode {
+				fn(fmt.Sprintf("pipeline[%d].tasks[%d]", i, j), task)
+			}
+		}
+	}
+}
+
+// mappingValue 返回映射节点中指定键的值节点
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// encodeNode 以两空格缩进编码节点树
+func encodeNode(doc *yaml.Node) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", fmt.Errorf("生成配置失败: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("生成配置失败: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// applyToOriginal 将迁移带来的变更应用到原始文本，保留空行与注释对齐
+// before/after 为迁移前后的规范化输出；before 的每一行都能在原文中找到对应行
+// （仅空白不同），因此只需把 before → after 的差异映射回原文。无法映射时退回 after
+func applyToOriginal(original, before, after string) []byte {
+	origLines := strings.Split(strings.TrimSuffix(original, "\n"), "\n")
+	beforeLines := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	afterLines := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+
+	// 原文行 ↔ 规范化行的对应关系
+	normOrig := make([]string, len(origLines))
+	for i, l := range origLines {
+		normOrig[i] = normalizeLine(l)
+	}
+	normBefore := make([]string, len(beforeLines))
+	for i, l := range beforeLines {
+		normBefore[i] = normalizeLine(l)
+	}
+
+	origAt := make([]int, 0, len(beforeLines)) // before 第 k 行对应的原文行号
+	oi := 0
+	for _, l := range textdiff.Lines(normOrig, normBefore) {
+		switch l.Kind {
+		case textdiff.OpEqual:
+			origAt = append(origAt, oi)
+			oi++
+		case textdiff.OpDelete:
+			oi++
+		case textdiff.OpInsert:
+			return []byte(after) // 原文中不存在的行，无法保留格式
+		}
+	}
+
+	var out []string
+	next := 0 // 下一个待输出的原文行
+	bi := 0   // 当前 before 行
+	for _, l := range textdiff.Lines(beforeLines, afterLines) {
+		switch l.Kind {
+		case textdiff.OpEqual:
+			out = append(out, origLines[next:origAt[bi]+1]...)
+			next = origAt[bi] + 1
+			bi++
+		case textdiff.OpDelete:
+			// 保留被删除行之前的空行等未映射内容
+			out = append(out, origLines[next:origAt[bi]]...)
+			next = origAt[bi] + 1
+			bi++
+		case textdiff.OpInsert:
+			out = append(out, l.Text)
+		}
+	}
+	out = append(out, origLines[next:]...)
+
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// normalizeLine 折叠空白，用于比较仅格式不同的行
+func normalizeLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
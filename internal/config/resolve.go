@@ -0,0 +1,237 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 变量来源
+const (
+	OriginVariables = "variables" // 配置文件 variables
+	OriginProfile   = "profile"   // profiles.<name>.variables
+	OriginCLI       = "cli"       // 命令行 --var
+	OriginEnv       = "env"       // 环境变量
+	OriginBuiltin   = "builtin"   // 内置变量
+	OriginRuntime   = "runtime"   // 运行时变量，流水线运行过程中才确定取值
+	OriginServer    = "server"    // 服务器变量，连接每台服务器时按服务器取值
+)
+
+// runtimeVarPrefix 运行时变量前缀，如 ${images.api.digest}
+const runtimeVarPrefix = "images."
+
+// serverVarPrefix 服务器变量前缀，如 ${server.name}、${server.region}
+const serverVarPrefix = "server."
+
+var (
+	bracedVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)              // ${VAR_NAME}
+	bareVarPattern   = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`) // $VAR_NAME
+)
+
+// Variable 解析后的变量
+type Variable struct {
+	Name   string
+	Value  string
+	Origin string // 来源，见 Origin* 常量
+	Detail string // 来源补充说明（如 profile 名称）
+}
+
+// Source 返回来源描述，如 "variables"、"profile:prod"
+func (v Variable) Source() string {
+	if v.Detail != "" {
+		return v.Origin + ":" + v.Detail
+	}
+	return v.Origin
+}
+
+// Substitution 一次变量替换记录
+type Substitution struct {
+	Ref      string    // 原始引用文本，如 ${APP_VERSION}
+	Name     string    // 变量名
+	Variable *Variable // 解析到的变量（未解析时为 nil）
+}
+
+// Resolver 变量解析器，记录每个变量的来源
+// 优先级: cli > profile > variables > builtin，均未定义时回退到环境变量
+type Resolver struct {
+	vars map[string]Variable
+}
+
+// newResolver 按优先级合并变量并展开变量间引用
+func newResolver(cfg *Config, configPath, profile string, overrides map[string]string) *Resolver {
+	r := &Resolver{vars: make(map[string]Variable)}
+
+	set := func(vars map[string]string, origin, detail string) {
+		for k, v := range vars {
+			r.vars[k] = Variable{Name: k, Value: v, Origin: origin, Detail: detail}
+		}
+	}
+
+	set(builtinVariables(cfg, configPath, profile), OriginBuiltin, "")
+	set(cfg.Variables, OriginVariables, "")
+	if p, ok := cfg.Profiles[profile]; ok {
+		set(p.Variables, OriginProfile, profile)
+	}
+	set(overrides, OriginCLI, "")
+
+	// 先对变量值本身进行展开（支持变量间引用）
+	// 多次迭代以支持嵌套引用
+	for i := 0; i < 10; i++ {
+		changed := false
+		for k, v := range r.vars {
+			expanded := r.Expand(v.Value)
+			if expanded != v.Value {
+				v.Value = expanded
+				r.vars[k] = v
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return r
+}
+
+// builtinVariables 返回内置变量
+func builtinVariables(cfg *Config, configPath, profile string) map[string]string {
+	vars := map[string]string{
+		"project.name": cfg.Project.Name,
+		"host.os":      runtime.GOOS,
+		"host.arch":    runtime.GOARCH,
+	}
+	if configPath != "" {
+		if abs, err := filepath.Abs(configPath); err == nil {
+			vars["config.dir"] = filepath.Dir(abs)
+		}
+	}
+	if profile != "" {
+		vars["profile"] = profile
+	}
+	return vars
+}
+
+// Lookup 查找变量，未定义时回退到环境变量（空值视为未定义）
+func (r *Resolver) Lookup(name string) (Variable, bool) {
+	if v, ok := r.vars[name]; ok {
+		return v, true
+	}
+	if val := os.Getenv(name); val != "" {
+		return Variable{Name: name, Value: val, Origin: OriginEnv}, true
+	}
+	return Variable{}, false
+}
+
+// Variables 返回所有已定义变量（不含环境变量回退，按名称排序）
+func (r *Resolver) Variables() []Variable {
+	vars := make([]Variable, 0, len(r.vars))
+	for _, v := range r.vars {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars
+}
+
+// Expand 展开字符串中的变量引用，未解析的引用保持原样
+// 支持格式: ${VAR_NAME} 和 $VAR_NAME
+func (r *Resolver) Expand(s string) string {
+	result, _ := r.ExpandTrace(s)
+	return result
+}
+
+// ExpandTrace 展开变量引用并返回替换记录（包括未解析的引用）
+func (r *Resolver) ExpandTrace(s string) (string, []Substitution) {
+	if s == "" {
+		return s, nil
+	}
+
+	var subs []Substitution
+	replace := func(match, name string) string {
+		sub := Substitution{Ref: match, Name: name}
+		result := match // 保持原样
+		if IsRuntimeVariable(name) {
+			// 运行时变量在加载阶段保持原样，由流水线在任务执行前替换
+			sub.Variable = &Variable{Name: name, Value: match, Origin: OriginRuntime}
+		} else if IsServerVariable(name) {
+			// 服务器变量在加载阶段保持原样，由 SSH 任务按连接的服务器替换
+			sub.Variable = &Variable{Name: name, Value: match, Origin: OriginServer}
+		} else if v, ok := r.Lookup(name); ok {
+			sub.Variable = &v
+			result = v.Value
+		}
+		subs = append(subs, sub)
+		return result
+	}
+
+	result := bracedVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		return replace(match, match[2:len(match)-1])
+	})
+	// 匹配 $VAR_NAME 格式（仅字母数字下划线）
+	result = bareVarPattern.ReplaceAllStringFunc(result, func(match string) string {
+		return replace(match, match[1:])
+	})
+
+	return result, subs
+}
+
+// IsRuntimeVariable 判断变量是否为运行时变量
+func IsRuntimeVariable(name string) bool {
+	return strings.HasPrefix(name, runtimeVarPrefix)
+}
+
+// ExpandRuntime 替换字符串中的运行时变量引用（仅 ${images.*} 形式），其余内容保持不变
+// 返回替换后的字符串及未能解析的引用
+func ExpandRuntime(s string, lookup func(name string) (string, bool)) (string, []string) {
+	var missing []string
+	result := bracedVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[2 : len(match)-1]
+		if !IsRuntimeVariable(name) {
+			return match
+		}
+		if v, ok := lookup(name); ok {
+			return v
+		}
+		missing = append(missing, match)
+		return match
+	})
+	return result, missing
+}
+
+// IsServerVariable 判断变量是否为服务器变量
+func IsServerVariable(name string) bool {
+	return strings.HasPrefix(name, serverVarPrefix)
+}
+
+// ServerVariableRefs 返回字符串中引用的服务器变量名（不含 server. 前缀，已去重）
+func ServerVariableRefs(s string) []string {
+	var names []string
+	for _, m := range bracedVarPattern.FindAllStringSubmatch(s, -1) {
+		if name, ok := strings.CutPrefix(m[1], serverVarPrefix); ok && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ExpandServer 替换字符串中的服务器变量引用（仅 ${server.*} 形式），vars 的键不含 server. 前缀
+// 返回替换后的字符串及未能解析的引用
+func ExpandServer(s string, vars map[string]string) (string, []string) {
+	var missing []string
+	result := bracedVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name, ok := strings.CutPrefix(match[2:len(match)-1], serverVarPrefix)
+		if !ok {
+			return match
+		}
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		missing = append(missing, match)
+		return match
+	})
+	return result, missing
+}
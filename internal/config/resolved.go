@@ -0,0 +1,163 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretMask 敏感值脱敏后的显示内容
+const secretMask = "******"
+
+// secretKeywords 字段名或变量名包含这些关键字时视为敏感信息
+var secretKeywords = []string{"password", "passwd", "passphrase", "secret", "token", "credential", "private_key"}
+
+// UnresolvedRef 未解析的变量引用
+type UnresolvedRef struct {
+	Path string // 配置项路径，如 registries.default.password
+	Ref  string // 引用文本，如 ${DOCKER_PASSWORD}
+}
+
+// ResolvedNode 返回变量替换后的配置节点树，敏感值已脱敏
+// variables 替换为最终生效的变量（含 profile 与 --var 覆盖），profiles 被移除；
+// explain 为 true 时在每个被替换的值后以注释标注变量来源
+func (c *Config) ResolvedNode(explain bool) (*yaml.Node, []UnresolvedRef, error) {
+	root := c.Source().Root()
+	if root == nil || c.resolver == nil {
+		return nil, nil, fmt.Errorf("配置未通过 Loader 加载")
+	}
+
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	var unresolved []UnresolvedRef
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := copyNode(root.Content[i])
+		val := root.Content[i+1]
+
+		switch key.Value {
+		case "profiles":
+			continue
+		case "variables":
+			out.Content = append(out.Content, key, c.resolvedVariables(explain))
+			continue
+		}
+
+		val = copyNode(val)
+		if key.Value == "registries" || key.Value == "servers" || key.Value == "pipeline" {
+			unresolved = append(unresolved, c.resolveNode(val, key.Value, explain)...)
+		}
+		out.Content = append(out.Content, key, val)
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{out}}, unresolved, nil
+}
+
+// resolvedVariables 生成最终生效的变量节点（不含内置变量）
+func (c *Config) resolvedVariables(explain bool) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, v := range c.resolver.Variables() {
+		if v.Origin == OriginBuiltin {
+			continue
+		}
+		val := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: v.Value}
+		if isSecretName(v.Name) && v.Value != "" {
+			val.Value = secretMask
+		}
+		if explain {
+			val.LineComment = "← " + v.Source()
+		}
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.Name}, val)
+	}
+	return node
+}
+
+// resolveNode 递归替换节点中的变量引用（与 Loader 的替换范围一致）
+// 流水线中仅替换任务 config，阶段和任务名称保持原样
+func (c *Config) resolveNode(node *yaml.Node, path string, explain bool) []UnresolvedRef {
+	var unresolved []UnresolvedRef
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			child := joinPath(path, key)
+			if strings.HasPrefix(path, "pipeline") && !strings.Contains(path, ".config") && key != "tasks" && key != "config" {
+				continue
+			}
+			unresolved = append(unresolved, c.resolveNode(node.Content[i+1], child, explain)...)
+		}
+
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			unresolved = append(unresolved, c.resolveNode(item, fmt.Sprintf("%s[%d]", path, i), explain)...)
+		}
+
+	case yaml.ScalarNode:
+		if node.Tag != "!!str" {
+			return nil
+		}
+		value, subs := c.resolver.ExpandTrace(node.Value)
+
+		// 敏感字段即使是明文也需脱敏；仅包含未解析引用时保留原样便于排查
+		secret := isSecretName(lastPathKey(path))
+		resolved := len(subs) == 0
+		notes := make([]string, 0, len(subs))
+		for _, sub := range subs {
+			if sub.Variable == nil {
+				unresolved = append(unresolved, UnresolvedRef{Path: path, Ref: sub.Ref})
+				notes = append(notes, sub.Ref+" ← 未定义")
+				continue
+			}
+			resolved = true
+			secret = secret || isSecretName(sub.Name)
+			notes = append(notes, sub.Ref+" ← "+sub.Variable.Source())
+		}
+
+		node.Value = value
+		if secret && resolved && value != "" {
+			node.Value = secretMask
+		}
+		if explain && len(notes) > 0 {
+			node.LineComment = strings.Join(notes, ", ")
+		}
+	}
+
+	return unresolved
+}
+
+// copyNode 深拷贝节点并去除原有注释
+func copyNode(node *yaml.Node) *yaml.Node {
+	n := *node
+	n.HeadComment, n.LineComment, n.FootComment = "", "", ""
+	if node.Alias != nil {
+		n.Alias = copyNode(node.Alias)
+	}
+	n.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		n.Content[i] = copyNode(child)
+	}
+	return &n
+}
+
+// isSecretName 判断字段名或变量名是否表示敏感信息
+func isSecretName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range secretKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastPathKey 返回路径中的最后一个键名
+func lastPathKey(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		path = path[i+1:]
+	}
+	if i := strings.Index(path, "["); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
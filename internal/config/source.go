@@ -0,0 +1,178 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source 配置文件源信息，保留 YAML 节点树用于定位配置项所在的行列
+type Source struct {
+	File  string
+	Lines []string
+	root  *yaml.Node
+}
+
+// Position 配置项在源文件中的位置（1-based）
+type Position struct {
+	Line   int
+	Column int
+}
+
+// FrameLine 代码片段中的一行
+type FrameLine struct {
+	Number    int    // 行号
+	Text      string // 行内容
+	Highlight bool   // 是否为出错行
+}
+
+// newSource 创建配置源信息
+func newSource(file string, data []byte, root *yaml.Node) *Source {
+	return &Source{
+		File:  file,
+		Lines: strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"),
+		root:  root,
+	}
+}
+
+// Root 返回 YAML 文档的根映射节点
+func (s *Source) Root() *yaml.Node {
+	if s == nil || s.root == nil {
+		return nil
+	}
+	if s.root.Kind == yaml.DocumentNode && len(s.root.Content) > 0 {
+		return s.root.Content[0]
+	}
+	return s.root
+}
+
+// Locate 根据逻辑路径（如 pipeline[3].tasks[1].config.registry）定位源文件位置
+// 路径中的键不存在时，返回最近一个存在的父节点位置
+func (s *Source) Locate(path string) (Position, bool) {
+	node := s.Root()
+	if node == nil {
+		return Position{}, false
+	}
+
+	pos := Position{Line: node.Line, Column: node.Column}
+	for _, seg := range splitPath(path) {
+		next, keyNode := childNode(node, seg)
+		if next == nil {
+			return pos, true
+		}
+		node = next
+		// 标量值定位到值本身；空值和嵌套结构定位到键，便于阅读
+		if keyNode != nil && (next.Kind != yaml.ScalarNode || next.Tag == "!!null") {
+			pos = Position{Line: keyNode.Line, Column: keyNode.Column}
+		} else {
+			pos = Position{Line: next.Line, Column: next.Column}
+		}
+	}
+	return pos, true
+}
+
+// newIssue 创建验证结果，按逻辑路径定位源文件位置（s 为 nil 时不含位置）
+func (s *Source) newIssue(severity, field, message string) ValidationError {
+	issue := ValidationError{Field: field, Message: message, Severity: severity}
+	if s == nil {
+		return issue
+	}
+	if pos, ok := s.Locate(field); ok {
+		issue.File = s.File
+		issue.Line = pos.Line
+		issue.Column = pos.Column
+	}
+	return issue
+}
+
+// newIssueAt 在指定节点位置创建验证结果
+func (s *Source) newIssueAt(severity, field, message string, node *yaml.Node) ValidationError {
+	issue := ValidationError{Field: field, Message: message, Severity: severity, Line: node.Line, Column: node.Column}
+	if s != nil {
+		issue.File = s.File
+	}
+	return issue
+}
+
+// CodeFrame 返回出错位置附近的代码片段，context 为上下文行数
+func (s *Source) CodeFrame(pos Position, context int) []FrameLine {
+	if s == nil || pos.Line <= 0 || pos.Line > len(s.Lines) {
+		return nil
+	}
+
+	start := pos.Line - context
+	if start < 1 {
+		start = 1
+	}
+	end := pos.Line + context
+	if end > len(s.Lines) {
+		end = len(s.Lines)
+	}
+
+	frame := make([]FrameLine, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		frame = append(frame, FrameLine{
+			Number:    i,
+			Text:      s.Lines[i-1],
+			Highlight: i == pos.Line,
+		})
+	}
+	return frame
+}
+
+// pathSegment 逻辑路径中的一段：键名 + 可选下标
+type pathSegment struct {
+	key   string
+	index int // -1 表示无下标
+}
+
+// splitPath 解析逻辑路径
+// 支持格式: a.b, a[0].b, a[0][1]
+func splitPath(path string) []pathSegment {
+	var segs []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			continue
+		}
+		key := part
+		rest := ""
+		if i := strings.Index(part, "["); i >= 0 {
+			key, rest = part[:i], part[i:]
+		}
+		if key != "" {
+			segs = append(segs, pathSegment{key: key, index: -1})
+		}
+		for strings.HasPrefix(rest, "[") {
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				break
+			}
+			if n, err := strconv.Atoi(rest[1:end]); err == nil {
+				segs = append(segs, pathSegment{index: n})
+			}
+			rest = rest[end+1:]
+		}
+	}
+	return segs
+}
+
+// childNode 获取子节点，返回值节点和（映射时的）键节点
+func childNode(node *yaml.Node, seg pathSegment) (*yaml.Node, *yaml.Node) {
+	if seg.key != "" {
+		if node.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == seg.key {
+				return node.Content[i+1], node.Content[i]
+			}
+		}
+		return nil, nil
+	}
+
+	if node.Kind != yaml.SequenceNode || seg.index < 0 || seg.index >= len(node.Content) {
+		return nil, nil
+	}
+	return node.Content[seg.index], nil
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/sshconfig"
+)
+
+// defaultIdentityFiles ssh 配置未指定 IdentityFile 时尝试的私钥（与 OpenSSH 一致）
+var defaultIdentityFiles = []string{"~/.ssh/id_rsa", "~/.ssh/id_ecdsa", "~/.ssh/id_ed25519"}
+
+// resolveSSHConfig 为设置了 ssh_config_host 的服务器从 ssh 配置补全连接参数
+// 无法解析时记录错误，由 Validator 报告
+func resolveSSHConfig(cfg *Config) {
+	files := make(map[string]*sshconfig.Config)
+	for name, srv := range cfg.Servers {
+		if srv.SSHConfigHost == "" {
+			continue
+		}
+		field := fmt.Sprintf("servers.%s.ssh_config_host", name)
+
+		path := sshconfig.DefaultPath()
+		if srv.SSHConfigFile != "" {
+			path = expandHomePath(srv.SSHConfigFile)
+		}
+		sc, ok := files[path]
+		if !ok {
+			var err error
+			if sc, err = sshconfig.Load(path); err != nil {
+				cfg.issues = append(cfg.issues, cfg.newIssue(SeverityError, field, fmt.Sprintf("读取 ssh 配置失败: %v", err)))
+				continue
+			}
+			files[path] = sc
+		}
+
+		host, err := sc.Get(srv.SSHConfigHost)
+		if err != nil {
+			cfg.issues = append(cfg.issues, cfg.newIssue(SeverityError, field, fmt.Sprintf("解析 ssh 配置失败: %v", err)))
+			continue
+		}
+		if !host.Matched {
+			cfg.issues = append(cfg.issues, cfg.newIssue(SeverityError, field,
+				fmt.Sprintf("%s 中没有 Host %s", path, srv.SSHConfigHost)))
+			continue
+		}
+
+		warnings := srv.applySSHConfig(host)
+		// 显式配置的 jump 优先于 ProxyJump
+		if srv.ProxyJump != "" && srv.Jump == "" {
+			hops, hopWarnings, err := proxyHops(sc, srv.ProxyJump)
+			if err != nil {
+				cfg.issues = append(cfg.issues, cfg.newIssue(SeverityError, field, fmt.Sprintf("解析 ProxyJump 失败: %v", err)))
+				continue
+			}
+			srv.ProxyHops = hops
+			warnings = append(warnings, hopWarnings...)
+		}
+		for _, w := range warnings {
+			cfg.warnings = append(cfg.warnings, cfg.newIssue(SeverityWarning, field, w))
+		}
+		cfg.Servers[name] = srv
+	}
+}
+
+// hasIssue 检查加载时是否记录了指定路径前缀的错误
+func (c *Config) hasIssue(prefix string) bool {
+	for _, issue := range c.issues {
+		if strings.HasPrefix(issue.Field, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySSHConfig 用 ssh 配置补全未显式设置的字段，返回不支持的选项的警告
+func (s *Server) applySSHConfig(h *sshconfig.Host) []string {
+	var warnings []string
+
+	if s.Host == "" {
+		s.Host = h.HostName
+	}
+	if s.Port == 0 {
+		s.Port = h.Port
+		if s.Port == 0 {
+			s.Port = 22
+		}
+	}
+	if s.Username == "" {
+		s.Username = h.User
+		if s.Username == "" {
+			if u, err := user.Current(); err == nil {
+				s.Username = u.Username
+			}
+		}
+	}
+	if s.KnownHosts == "" {
+		s.KnownHosts = h.UserKnownHostsFile
+	}
+	if s.HostKeyPolicy == "" {
+		switch h.StrictHostKeyChecking {
+		case "yes", "ask":
+			s.HostKeyPolicy = HostKeyStrict
+		case "accept-new":
+			s.HostKeyPolicy = HostKeyTOFU
+		case "no", "off":
+			s.HostKeyPolicy = HostKeyInsecure
+		}
+	}
+	if h.ProxyJump != "" && !strings.EqualFold(h.ProxyJump, "none") {
+		s.ProxyJump = h.ProxyJump
+	}
+	if h.ProxyCommand != "" && !strings.EqualFold(h.ProxyCommand, "none") {
+		warnings = append(warnings, fmt.Sprintf("不支持 ssh 配置中的 ProxyCommand，已忽略: %s", h.ProxyCommand))
+	}
+
+	if s.Auth.Type == "" && len(s.Auth.Methods) == 0 {
+		useAgent := !h.IdentitiesOnly && h.IdentityAgent != "none" && os.Getenv("SSH_AUTH_SOCK") != ""
+		if useAgent && h.IdentityAgent != "" && h.IdentityAgent != "SSH_AUTH_SOCK" {
+			warnings = append(warnings, fmt.Sprintf("不支持 ssh 配置中的 IdentityAgent %s，使用 SSH_AUTH_SOCK", h.IdentityAgent))
+		}
+		s.Auth = sshConfigAuth(h, useAgent)
+	}
+	return warnings
+}
+
+// proxyHops 将 ProxyJump（逗号分隔的 [user@]host[:port]）解析为跳板机，各跳板机同样从 ssh 配置补全参数
+func proxyHops(sc *sshconfig.Config, proxyJump string) ([]Server, []string, error) {
+	var hops []Server
+	var warnings []string
+	for _, spec := range strings.Split(proxyJump, ",") {
+		username, host, port, err := parseJumpSpec(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, nil, err
+		}
+		h, err := sc.Get(host)
+		if err != nil {
+			return nil, nil, err
+		}
+		hop := Server{Username: username, Port: port, SSHConfigHost: host}
+		warnings = append(warnings, hop.applySSHConfig(h)...)
+		// 与 OpenSSH 一致，跳板机自身的 ProxyJump 不再展开
+		hop.ProxyJump = ""
+		if hop.Auth.Type == "" && len(hop.Auth.Methods) == 0 {
+			return nil, nil, fmt.Errorf("跳板机 %s 没有可用的 IdentityFile，且 SSH_AUTH_SOCK 未设置", host)
+		}
+		hops = append(hops, hop)
+	}
+	return hops, warnings, nil
+}
+
+// parseJumpSpec 解析单个跳板机：[user@]host[:port] 或 ssh://[user@]host[:port]
+func parseJumpSpec(spec string) (username, host string, port int, err error) {
+	host = strings.TrimPrefix(spec, "ssh://")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		username, host = host[:i], host[i+1:]
+	}
+	if strings.HasPrefix(host, "[") || strings.Count(host, ":") == 1 {
+		h, p, splitErr := net.SplitHostPort(host)
+		if splitErr != nil {
+			return "", "", 0, fmt.Errorf("无效的跳板机: %s", spec)
+		}
+		if port, err = strconv.Atoi(p); err != nil || port <= 0 || port > 65535 {
+			return "", "", 0, fmt.Errorf("无效的跳板机端口: %s", spec)
+		}
+		host = h
+	}
+	if host == "" {
+		return "", "", 0, fmt.Errorf("无效的跳板机: %s", spec)
+	}
+	return username, host, port, nil
+}
+
+// sshConfigAuth 根据 ssh 配置生成认证方式：ssh-agent 在前，之后为存在的 IdentityFile
+func sshConfigAuth(h *sshconfig.Host, useAgent bool) ServerAuth {
+	var methods []ServerAuth
+	if useAgent {
+		methods = append(methods, ServerAuth{Type: AuthAgent})
+	}
+
+	identities := h.IdentityFiles
+	if len(identities) == 0 {
+		for _, f := range defaultIdentityFiles {
+			identities = append(identities, expandHomePath(f))
+		}
+	}
+	for i, f := range identities {
+		// 与 OpenSSH 一致，忽略不存在的私钥
+		if !fileExists(f) {
+			continue
+		}
+		m := ServerAuth{Type: AuthKey, KeyPath: f}
+		if i < len(h.CertificateFiles) && fileExists(h.CertificateFiles[i]) {
+			m.Certificate = h.CertificateFiles[i]
+		}
+		methods = append(methods, m)
+	}
+
+	if len(methods) == 1 {
+		return methods[0]
+	}
+	return ServerAuth{Methods: methods}
+}
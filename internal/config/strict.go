@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var taskType = reflect.TypeOf(Task{})
+
+// validateFields 基于 YAML 节点树检查未知字段和不适用于任务类型的字段
+func (v *Validator) validateFields() {
+	root := v.config.Source().Root()
+	if root == nil {
+		return
+	}
+	v.checkNode(root, reflect.TypeOf(Config{}), "")
+}
+
+// checkNode 按目标类型递归检查节点中的字段
+func (v *Validator) checkNode(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valNode := node.Content[i], node.Content[i+1]
+			key := keyNode.Value
+			field, ok := fields[key]
+			if !ok {
+				v.addErrorAt(joinPath(path, key), unknownFieldMessage(key, sortedKeys(fields)), keyNode)
+				continue
+			}
+			v.checkDeprecated(field, joinPath(path, key), keyNode)
+			if t == taskType && key == "config" {
+				v.checkTaskConfig(node, valNode, joinPath(path, key))
+				continue
+			}
+			v.checkNode(valNode, field.Type, joinPath(path, key))
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.checkNode(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			v.checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// checkTaskConfig 检查任务 config 中的字段是否适用于声明的任务类型
+func (v *Validator) checkTaskConfig(taskNode, configNode *yaml.Node, path string) {
+	if configNode.Kind != yaml.MappingNode {
+		return
+	}
+
+	var declared string
+	for i := 0; i+1 < len(taskNode.Content); i += 2 {
+		if taskNode.Content[i].Value == "type" {
+			declared = taskNode.Content[i+1].Value
+		}
+	}
+
+	// 未注册的任务类型已在 validateTask 中报告
+	settings, ok := NewTaskSettings(declared)
+	if !ok {
+		return
+	}
+	fields := yamlFields(reflect.TypeOf(settings))
+	allowed := sortedKeys(fields)
+
+	for i := 0; i+1 < len(configNode.Content); i += 2 {
+		keyNode, valNode := configNode.Content[i], configNode.Content[i+1]
+		key := keyNode.Value
+
+		field, ok := fields[key]
+		if ok {
+			v.checkDeprecated(field, joinPath(path, key), keyNode)
+			v.checkNode(valNode, field.Type, joinPath(path, key))
+			continue
+		}
+
+		// 属于其他任务类型的字段
+		if owners := taskTypesWithField(key); len(owners) > 0 {
+			v.addErrorAt(joinPath(path, key),
+				fmt.Sprintf("字段 %q 不适用于任务类型 %s (适用于: %s)", key, declared, strings.Join(owners, ", ")),
+				keyNode)
+			continue
+		}
+
+		// 完全未知的字段，优先在当前任务类型的字段中寻找相近拼写
+		candidates := allowed
+		if suggest(key, allowed) == "" {
+			candidates = allTaskFields()
+		}
+		v.addErrorAt(joinPath(path, key), unknownFieldMessage(key, candidates), keyNode)
+	}
+}
+
+// checkDeprecated 检查字段是否已废弃
+// 废弃字段通过 deprecated 标签声明替代字段，如 `yaml:"old_name" deprecated:"new_name"`
+func (v *Validator) checkDeprecated(field reflect.StructField, path string, keyNode *yaml.Node) {
+	replacement := field.Tag.Get("deprecated")
+	if replacement == "" {
+		return
+	}
+	v.addWarningAt(path,
+		fmt.Sprintf("字段 %q 已废弃，请改用 %q（运行 xbuilder migrate 可自动迁移）", keyNode.Value, replacement),
+		keyNode)
+}
+
+// yamlFields 返回结构体的 YAML 字段（键名 -> 字段），展开 inline 字段
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			for k, sub := range yamlFields(f.Type) {
+				fields[k] = sub
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f
+	}
+	return fields
+}
+
+// taskTypesWithField 返回包含指定字段的任务类型
+func taskTypesWithField(key string) []string {
+	var types []string
+	for _, t := range TaskTypes() {
+		settings, _ := NewTaskSettings(t)
+		if _, ok := yamlFields(reflect.TypeOf(settings))[key]; ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// allTaskFields 返回所有任务类型的字段名（去重、已排序）
+func allTaskFields() []string {
+	all := make(map[string]reflect.StructField)
+	for _, t := range TaskTypes() {
+		settings, _ := NewTaskSettings(t)
+		for k, f := range yamlFields(reflect.TypeOf(settings)) {
+			all[k] = f
+		}
+	}
+	return sortedKeys(all)
+}
+
+// unknownFieldMessage 生成未知字段的错误信息，附带拼写建议
+func unknownFieldMessage(key string, candidates []string) string {
+	if s := suggest(key, candidates); s != "" {
+		return fmt.Sprintf("未知字段 %q，是否想使用 %q?", key, s)
+	}
+	return fmt.Sprintf("未知字段 %q", key)
+}
+
+// suggest 返回编辑距离最近的候选项（差异过大时返回空）
+func suggest(key string, candidates []string) string {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(key), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+
+	limit := len(key) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if bestDist < 0 || bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+// levenshtein 计算两个字符串的编辑距离
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// joinPath 拼接逻辑路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedKeys 返回排序后的字段名列表
+func sortedKeys(fields map[string]reflect.StructField) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TaskSettings 任务类型的配置结构，由各执行器包注册
+type TaskSettings interface {
+	// Common 返回所有任务类型共享的通用配置
+	Common() *CommonConfig
+
+	// Validate 校验配置，错误通过 TaskValidation 报告
+	Validate(v *TaskValidation)
+
+	// Expand 展开配置中字符串字段的变量引用
+	Expand(expand func(string) string)
+}
+
+// OutputBatcher 支持 force_refresh（合并输出，降低刷新频率）的任务配置
+type OutputBatcher interface {
+	BatchOutput() bool
+}
+
+// ServerTargeted 面向远程服务器的任务配置，用于按服务器过滤任务
+type ServerTargeted interface {
+	TargetServers() []string
+}
+
+// GroupTargeted 可通过服务器组（server_group）指定目标服务器的任务配置
+type GroupTargeted interface {
+	TargetGroup() string
+}
+
+// TagTargeted 可通过服务器标签（server_tag）指定目标服务器的任务配置
+type TagTargeted interface {
+	TargetTag() string
+}
+
+// CommonConfig 所有任务类型共享的配置（以 inline 方式嵌入各类型配置）
+type CommonConfig struct {
+	Timeout int `yaml:"timeout,omitempty"` // 超时时间（秒）
+}
+
+// Common 返回通用配置
+func (c *CommonConfig) Common() *CommonConfig {
+	return c
+}
+
+// RefreshConfig force_refresh 配置（Docker 构建 / SSH 远程输出可选降级刷新）
+type RefreshConfig struct {
+	ForceRefresh bool `yaml:"force_refresh,omitempty"` // 强制降级刷新（避免刷屏）
+}
+
+// BatchOutput 返回是否合并输出
+func (c RefreshConfig) BatchOutput() bool {
+	return c.ForceRefresh
+}
+
+// TaskConfig 任务原始配置
+// 加载时仅保留 YAML 节点，使用时按任务类型延迟解码为注册的配置结构
+type TaskConfig struct {
+	node   *yaml.Node
+	expand func(string) string
+}
+
+// UnmarshalYAML 保留原始节点，延迟解码
+func (c *TaskConfig) UnmarshalYAML(node *yaml.Node) error {
+	c.node = node
+	return nil
+}
+
+// Node 返回原始 YAML 节点（未配置 config 时为 nil）
+func (c TaskConfig) Node() *yaml.Node {
+	return c.node
+}
+
+// Decode 按任务类型解码配置，并展开变量引用
+func (c TaskConfig) Decode(taskType string) (TaskSettings, error) {
+	settings, ok := NewTaskSettings(taskType)
+	if !ok {
+		return nil, fmt.Errorf("不支持的任务类型: %s", taskType)
+	}
+
+	if c.node != nil {
+		if err := c.node.Decode(settings); err != nil {
+			return nil, fmt.Errorf("解析任务配置失败: %w", err)
+		}
+	}
+
+	if c.expand != nil {
+		settings.Expand(c.expand)
+	}
+
+	return settings, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────
+// 任务类型注册表
+// ─────────────────────────────────────────────────────────────────────
+
+var (
+	taskTypesMu sync.RWMutex
+	taskTypes   = make(map[string]func() TaskSettings)
+)
+
+// RegisterTaskType 注册任务类型的配置结构
+// 一般由 executor.Register 调用，执行器包无需直接使用
+func RegisterTaskType(taskType string, newSettings func() TaskSettings) {
+	taskTypesMu.Lock()
+	defer taskTypesMu.Unlock()
+
+	if _, exists := taskTypes[taskType]; exists {
+		panic(fmt.Sprintf("任务类型重复注册: %s", taskType))
+	}
+	taskTypes[taskType] = newSettings
+}
+
+// NewTaskSettings 创建指定任务类型的空配置结构
+func NewTaskSettings(taskType string) (TaskSettings, bool) {
+	taskTypesMu.RLock()
+	defer taskTypesMu.RUnlock()
+
+	newSettings, ok := taskTypes[taskType]
+	if !ok {
+		return nil, false
+	}
+	return newSettings(), true
+}
+
+// TaskTypes 返回所有已注册的任务类型（已排序）
+func TaskTypes() []string {
+	taskTypesMu.RLock()
+	defer taskTypesMu.RUnlock()
+
+	types := make([]string, 0, len(taskTypes))
+	for t := range taskTypes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ─────────────────────────────────────────────────────────────────────
+// 任务配置校验上下文
+// ─────────────────────────────────────────────────────────────────────
+
+// TaskValidation 任务配置校验上下文
+type TaskValidation struct {
+	validator *Validator
+	path      string // 任务路径，如 pipeline[0].tasks[1]
+}
+
+// Config 返回完整配置（用于检查 registries、servers 等引用）
+func (t *TaskValidation) Config() *Config {
+	return t.validator.config
+}
+
+// AddError 添加错误，field 为相对于任务 config 的字段名（为空表示 config 本身）
+func (t *TaskValidation) AddError(field, message string) {
+	path := t.path + ".config"
+	if field != "" {
+		path += "." + field
+	}
+	t.validator.addError(path, message)
+}
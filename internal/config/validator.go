@@ -3,19 +3,44 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v3"
+)
+
+// 验证结果级别
+const (
+	SeverityError   = "error"   // 错误，配置不可用
+	SeverityWarning = "warning" // 警告，如已废弃的字段或过时的配置版本
 )
 
 // ValidationError 验证错误
 type ValidationError struct {
-	Field   string
-	Message string
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`         // 级别: error | warning
+	File     string `json:"file,omitempty"`   // 配置文件路径
+	Line     int    `json:"line,omitempty"`   // 行号 (1-based)
+	Column   int    `json:"column,omitempty"` // 列号 (1-based)
 }
 
+// Error 返回错误描述，有位置信息时使用 file:line:col 格式，便于编辑器和 CI 识别
 func (e ValidationError) Error() string {
+	if e.File != "" && e.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Field, e.Message)
+	}
 	return fmt.Sprintf("%s: %s", e.Field, e.Message)
 }
 
+// Position 返回错误在源文件中的位置
+func (e ValidationError) Position() Position {
+	return Position{Line: e.Line, Column: e.Column}
+}
+
 // ValidationErrors 多个验证错误
 type ValidationErrors []ValidationError
 
@@ -29,8 +54,9 @@ func (e ValidationErrors) Error() string {
 
 // Validator 配置验证器
 type Validator struct {
-	config *Config
-	errors ValidationErrors
+	config   *Config
+	errors   ValidationErrors
+	warnings ValidationErrors
 }
 
 // NewValidator 创建验证器
@@ -40,12 +66,19 @@ func NewValidator(cfg *Config) *Validator {
 
 // Validate 执行完整验证
 func (v *Validator) Validate() error {
-	v.errors = nil
+	v.errors = append(ValidationErrors(nil), v.config.issues...)
+	v.warnings = nil
 
 	v.validateProject()
 	v.validateRegistries()
 	v.validateServers()
+	v.validateGroups()
 	v.validatePipeline()
+	v.validateFields()
+
+	// 按源文件位置排序，便于逐条对照
+	sortByPosition(v.errors)
+	sortByPosition(v.warnings)
 
 	if len(v.errors) > 0 {
 		return v.errors
@@ -53,6 +86,27 @@ func (v *Validator) Validate() error {
 	return nil
 }
 
+// Warnings 返回警告（含加载时的版本检查结果），需在 Validate 之后调用
+func (v *Validator) Warnings() ValidationErrors {
+	warnings := append(ValidationErrors{}, v.config.Warnings()...)
+	warnings = append(warnings, v.warnings...)
+	sortByPosition(warnings)
+	return warnings
+}
+
+// sortByPosition 按源文件位置排序（inventory 文件中的问题按文件分开排列）
+func sortByPosition(errs ValidationErrors) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].File != errs[j].File {
+			return errs[i].File < errs[j].File
+		}
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+}
+
 // validateProject 验证项目配置
 func (v *Validator) validateProject() {
 	if v.config.Project.Name == "" {
@@ -63,15 +117,49 @@ func (v *Validator) validateProject() {
 // validateRegistries 验证 Registry 配置
 func (v *Validator) validateRegistries() {
 	for name, reg := range v.config.Registries {
+		path := fmt.Sprintf("registries.%s", name)
 		if reg.URL == "" {
-			v.addError(fmt.Sprintf("registries.%s.url", name), "Registry URL 不能为空")
+			v.addError(path+".url", "Registry URL 不能为空")
+		}
+
+		// 登录方式: username/password、credential_helper、use_docker_config 三选一
+		modes := 0
+		if reg.Username != "" || reg.Password != "" {
+			modes++
+			if reg.Username == "" {
+				v.addError(path+".username", "设置了 password 时用户名不能为空")
+			} else if reg.Password == "" {
+				v.addError(path+".password", "设置了 username 时密码不能为空（如需复用已有登录请使用 use_docker_config: true）")
+			}
+		}
+		if reg.CredentialHelper != "" {
+			modes++
+			if !credentialHelperPattern.MatchString(reg.CredentialHelper) {
+				v.addError(path+".credential_helper", fmt.Sprintf("无效的 credential helper 名称: %s（填写 docker-credential- 之后的部分，如 ecr-login）", reg.CredentialHelper))
+			}
+		}
+		if reg.UseDockerConfig {
+			modes++
+			if reg.LogoutAfter {
+				v.addError(path+".logout_after", "use_docker_config 复用已有登录，不能与 logout_after 同时使用")
+			}
+		}
+		if modes > 1 {
+			v.addError(path, "username/password、credential_helper 与 use_docker_config 只能选择一种登录方式")
 		}
 	}
 }
 
+// credentialHelperPattern docker-credential-<helper> 中 helper 的格式
+var credentialHelperPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
 // validateServers 验证服务器配置
 func (v *Validator) validateServers() {
 	for name, srv := range v.config.Servers {
+		// ssh_config_host 无法解析时已报告，不再检查缺失的字段
+		if v.config.hasIssue(fmt.Sprintf("servers.%s.", name)) {
+			continue
+		}
 		if srv.Host == "" {
 			v.addError(fmt.Sprintf("servers.%s.host", name), "服务器地址不能为空")
 		}
@@ -82,30 +170,177 @@ func (v *Validator) validateServers() {
 			v.addError(fmt.Sprintf("servers.%s.username", name), "用户名不能为空")
 		}
 
-		// 验证认证配置
-		switch srv.Auth.Type {
-		case "password":
-			if srv.Auth.Password == "" {
-				v.addError(fmt.Sprintf("servers.%s.auth.password", name), "密码不能为空")
+		if srv.SSHConfigHost != "" && srv.Auth.Type == "" && len(srv.Auth.Methods) == 0 {
+			v.addError(fmt.Sprintf("servers.%s.ssh_config_host", name),
+				"ssh 配置中没有可用的 IdentityFile，且 SSH_AUTH_SOCK 未设置，请在 auth 中配置认证方式")
+		} else {
+			v.validateAuth(name, srv.Auth)
+		}
+		v.validateHostKey(name, srv)
+
+		if srv.Jump != "" {
+			if _, err := v.config.Route(name); err != nil {
+				v.addError(fmt.Sprintf("servers.%s.jump", name), err.Error())
 			}
-		case "key":
-			if srv.Auth.KeyPath == "" {
-				v.addError(fmt.Sprintf("servers.%s.auth.key_path", name), "密钥路径不能为空")
-			} else {
-				// 检查密钥文件是否存在
-				keyPath := expandHomePath(srv.Auth.KeyPath)
-				if _, err := os.Stat(keyPath); os.IsNotExist(err) {
-					v.addError(fmt.Sprintf("servers.%s.auth.key_path", name),
-						fmt.Sprintf("密钥文件不存在: %s", keyPath))
-				}
+		}
+		v.validateLabels(name, srv)
+	}
+}
+
+// validateLabels 验证服务器的标签、所属组与变量
+func (v *Validator) validateLabels(name string, srv Server) {
+	for i, tag := range srv.Tags {
+		if !labelPattern.MatchString(tag) {
+			v.addError(fmt.Sprintf("servers.%s.tags[%d]", name, i), fmt.Sprintf("无效的标签: %q（只能包含字母、数字、下划线、点和连字符）", tag))
+		}
+	}
+	for i, group := range srv.Groups {
+		if !labelPattern.MatchString(group) {
+			v.addError(fmt.Sprintf("servers.%s.groups[%d]", name, i), fmt.Sprintf("无效的服务器组名称: %q（只能包含字母、数字、下划线、点和连字符）", group))
+		}
+	}
+	for key := range srv.Vars {
+		path := fmt.Sprintf("servers.%s.vars.%s", name, key)
+		if !serverVarPattern.MatchString(key) {
+			v.addError(path, fmt.Sprintf("无效的变量名: %q（只能包含字母、数字、下划线和连字符，且不能以数字开头）", key))
+		} else if slices.Contains(BuiltinServerVariables, key) {
+			v.addError(path, fmt.Sprintf("%s 为内置的服务器变量，不能在 vars 中定义", key))
+		}
+	}
+}
+
+var (
+	labelPattern     = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`) // 标签与服务器组名称
+	serverVarPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)     // 服务器变量名
+)
+
+// validateGroups 验证服务器组
+func (v *Validator) validateGroups() {
+	for name, members := range v.config.ServerGroups {
+		if len(v.config.GroupMembers(name)) == 0 {
+			v.addError("server_groups."+name, "服务器组为空，请添加成员或在服务器的 groups 中声明")
+			continue
+		}
+		seen := make(map[string]bool)
+		for i, member := range members {
+			path := fmt.Sprintf("server_groups.%s[%d]", name, i)
+			if _, ok := v.config.Servers[member]; !ok {
+				v.addError(path, fmt.Sprintf("服务器不存在: %s", member))
+			} else if seen[member] {
+				v.addError(path, fmt.Sprintf("服务器重复: %s", member))
 			}
-		default:
-			v.addError(fmt.Sprintf("servers.%s.auth.type", name),
-				fmt.Sprintf("无效的认证类型: %s (支持: password, key)", srv.Auth.Type))
+			seen[member] = true
+		}
+	}
+}
+
+// validateAuth 验证 SSH 认证配置
+func (v *Validator) validateAuth(name string, auth ServerAuth) {
+	path := fmt.Sprintf("servers.%s.auth", name)
+	if len(auth.Methods) == 0 {
+		v.validateAuthMethod(path, auth)
+		return
+	}
+
+	if auth.Type != "" || auth.Password != "" || auth.KeyPath != "" || auth.Passphrase != "" || auth.Certificate != "" {
+		v.addError(path, "设置 methods 时认证方式需写在 methods 中，不能同时设置 type 等字段")
+	}
+	seen := make(map[string]bool)
+	for i, m := range auth.Methods {
+		methodPath := fmt.Sprintf("%s.methods[%d]", path, i)
+		if len(m.Methods) > 0 {
+			v.addError(methodPath+".methods", "methods 不能嵌套")
+			continue
 		}
+		// 同一类型的认证每次连接只会尝试一次（key 除外，所有私钥合并为一次公钥认证）
+		if m.Type != AuthKey && seen[m.Type] {
+			v.addError(methodPath+".type", fmt.Sprintf("认证方式 %s 重复", m.Type))
+		}
+		seen[m.Type] = true
+		v.validateAuthMethod(methodPath, m)
 	}
 }
 
+// validateAuthMethod 验证单个认证方式
+func (v *Validator) validateAuthMethod(path string, auth ServerAuth) {
+	switch auth.Type {
+	case AuthPassword:
+		if auth.Password == "" {
+			v.addError(path+".password", "密码不能为空")
+		}
+	case AuthKey:
+		if auth.KeyPath == "" {
+			v.addError(path+".key_path", "密钥路径不能为空")
+		} else if keyPath := expandHomePath(auth.KeyPath); !fileExists(keyPath) {
+			v.addError(path+".key_path", fmt.Sprintf("密钥文件不存在: %s", keyPath))
+		}
+		if auth.Certificate != "" && !fileExists(expandHomePath(auth.Certificate)) {
+			v.addError(path+".certificate", fmt.Sprintf("证书文件不存在: %s", expandHomePath(auth.Certificate)))
+		}
+	case AuthAgent:
+		if os.Getenv("SSH_AUTH_SOCK") == "" {
+			v.addWarning(path+".type", "SSH_AUTH_SOCK 未设置，运行时无法连接 ssh-agent")
+		}
+	case AuthKeyboardInteractive:
+	default:
+		v.addError(path+".type",
+			fmt.Sprintf("无效的认证类型: %s (支持: %s)", auth.Type, strings.Join(AuthTypes, ", ")))
+	}
+
+	if auth.Type != AuthKey && auth.Type != "" {
+		if auth.Passphrase != "" {
+			v.addError(path+".passphrase", "passphrase 仅适用于 key 认证")
+		}
+		if auth.Certificate != "" {
+			v.addError(path+".certificate", "certificate 仅适用于 key 认证")
+		}
+	}
+}
+
+// fileExists 检查文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
+
+// validateHostKey 验证主机密钥校验配置，关闭校验时给出警告
+func (v *Validator) validateHostKey(name string, srv Server) {
+	path := fmt.Sprintf("servers.%s", name)
+	switch srv.Policy() {
+	case HostKeyStrict, HostKeyTOFU:
+	case HostKeyInsecure:
+		if srv.HostKey != "" {
+			v.addError(path+".host_key", "设置了 host_key 时不能使用 host_key_policy: insecure")
+		} else {
+			v.addWarning(path+".host_key_policy", "已关闭主机密钥校验，连接可能被中间人劫持；生产环境请使用 strict 或 tofu")
+		}
+	default:
+		v.addError(path+".host_key_policy",
+			fmt.Sprintf("无效的主机密钥校验策略: %s (支持: %s)", srv.HostKeyPolicy, strings.Join(HostKeyPolicies, ", ")))
+	}
+
+	if srv.HostKey != "" && !validHostKey(srv.HostKey) {
+		v.addError(path+".host_key", "无效的主机公钥，应为 SHA256:... 指纹（ssh-keygen -lf）或 \"ssh-ed25519 AAAA...\" 格式的公钥")
+	}
+	if srv.KnownHosts != "" && srv.HostKey == "" && srv.Policy() == HostKeyStrict {
+		if _, err := os.Stat(expandHomePath(srv.KnownHosts)); os.IsNotExist(err) {
+			v.addError(path+".known_hosts", fmt.Sprintf("known_hosts 文件不存在: %s（首次连接可使用 host_key_policy: tofu）", srv.KnownHosts))
+		}
+	}
+}
+
+// sha256FingerprintPattern ssh-keygen -lf 输出的 SHA256 指纹
+var sha256FingerprintPattern = regexp.MustCompile(`^SHA256:[A-Za-z0-9+/]{43}=?$`)
+
+// validHostKey 检查 host_key 是否为 SHA256 指纹或公钥
+func validHostKey(key string) bool {
+	if sha256FingerprintPattern.MatchString(key) {
+		return true
+	}
+	_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	return err == nil
+}
+
 // validatePipeline 验证流水线配置
 func (v *Validator) validatePipeline() {
 	if len(v.config.Pipeline) == 0 {
@@ -139,93 +374,45 @@ func (v *Validator) validatePipeline() {
 }
 
 // validateTask 验证任务配置
+// 通用字段在此校验，类型相关的校验由注册的配置结构完成
 func (v *Validator) validateTask(path string, task Task) {
 	if task.Name == "" {
 		v.addError(path+".name", "任务名称不能为空")
 	}
 
-	switch task.Type {
-	case TaskTypeMaven:
-		v.validateMavenTask(path, task)
-	case TaskTypeDockerBuild:
-		v.validateDockerBuildTask(path, task)
-	case TaskTypeDockerPush:
-		v.validateDockerPushTask(path, task)
-	case TaskTypeSSH:
-		v.validateSSHTask(path, task)
-	case TaskTypeGoBuild:
-		// Go 构建任务可接受默认参数；若使用 go test/generate，可按需补充校验
-	case TaskTypeShell:
-		v.validateShellTask(path, task)
-	default:
+	if _, ok := NewTaskSettings(task.Type); !ok {
 		v.addError(path+".type",
-			fmt.Sprintf("无效的任务类型: %s (支持: maven, docker-build, docker-push, ssh, go-build, shell)", task.Type))
-	}
-}
-
-// validateMavenTask 验证 Maven 任务
-func (v *Validator) validateMavenTask(path string, task Task) {
-	if task.Config.Command == "" && task.Config.Script == "" {
-		v.addError(path+".config", "Maven 任务必须指定 command 或 script")
+			fmt.Sprintf("无效的任务类型: %s (支持: %s)", task.Type, strings.Join(TaskTypes(), ", ")))
+		return
 	}
-}
 
-// validateDockerBuildTask 验证 Docker Build 任务
-func (v *Validator) validateDockerBuildTask(path string, task Task) {
-	// 如果启用了自动扫描，则不需要其他配置
-	if task.Config.AutoScan != nil && task.Config.AutoScan.Enabled {
-		if task.Config.AutoScan.Pattern == "" {
-			v.addError(path+".config.auto_scan.pattern", "自动扫描模式不能为空")
-		}
+	settings, err := task.Settings()
+	if err != nil {
+		v.addError(path+".config", err.Error())
 		return
 	}
 
-	if task.Config.Dockerfile == "" {
-		v.addError(path+".config.dockerfile", "Dockerfile 路径不能为空")
-	}
-	if task.Config.ImageName == "" {
-		v.addError(path+".config.image_name", "镜像名称不能为空")
-	}
+	settings.Validate(&TaskValidation{validator: v, path: path})
 }
 
-// validateDockerPushTask 验证 Docker Push 任务
-func (v *Validator) validateDockerPushTask(path string, task Task) {
-	if task.Config.Registry == "" {
-		v.addError(path+".config.registry", "Registry 名称不能为空")
-	} else if _, ok := v.config.Registries[task.Config.Registry]; !ok {
-		v.addError(path+".config.registry",
-			fmt.Sprintf("Registry 不存在: %s", task.Config.Registry))
-	}
-
-	if len(task.Config.Images) == 0 && !task.Config.Auto {
-		v.addError(path+".config", "必须指定 images 列表或设置 auto: true")
-	}
+// addError 添加验证错误（有配置源信息时附加行列位置）
+func (v *Validator) addError(field, message string) {
+	v.errors = append(v.errors, v.config.newIssue(SeverityError, field, message))
 }
 
-// validateSSHTask 验证 SSH 任务
-func (v *Validator) validateSSHTask(path string, task Task) {
-	if task.Config.Server == "" {
-		v.addError(path+".config.server", "服务器名称不能为空")
-	} else if _, ok := v.config.Servers[task.Config.Server]; !ok {
-		v.addError(path+".config.server",
-			fmt.Sprintf("服务器不存在: %s", task.Config.Server))
-	}
-
-	if len(task.Config.Commands) == 0 && task.Config.Script == "" && task.Config.LocalScript == "" {
-		v.addError(path+".config", "必须指定 commands、script 或 local_script")
-	}
+// addErrorAt 在指定节点位置添加验证错误
+func (v *Validator) addErrorAt(field, message string, node *yaml.Node) {
+	v.errors = append(v.errors, v.config.Source().newIssueAt(SeverityError, field, message, node))
 }
 
-// validateShellTask 验证 Shell 任务
-func (v *Validator) validateShellTask(path string, task Task) {
-	if task.Config.Command == "" && task.Config.Script == "" {
-		v.addError(path+".config", "Shell 任务必须指定 command 或 script")
-	}
+// addWarning 添加警告（有配置源信息时附加行列位置）
+func (v *Validator) addWarning(field, message string) {
+	v.warnings = append(v.warnings, v.config.newIssue(SeverityWarning, field, message))
 }
 
-// addError 添加验证错误
-func (v *Validator) addError(field, message string) {
-	v.errors = append(v.errors, ValidationError{Field: field, Message: message})
+// addWarningAt 在指定节点位置添加警告
+func (v *Validator) addWarningAt(field, message string, node *yaml.Node) {
+	v.warnings = append(v.warnings, v.config.Source().newIssueAt(SeverityWarning, field, message, node))
 }
 
 // expandHomePath 展开 ~ 为 home 目录
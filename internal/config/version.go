@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CurrentVersion 当前配置格式版本
+// 格式变更（字段重命名、结构调整）时递增，并在 migrations 中登记迁移步骤
+const CurrentVersion = "1.1"
+
+// SupportedVersions 可加载的配置版本（旧版本会提示运行 xbuilder migrate）
+var SupportedVersions = []string{"1.0", "1.1"}
+
+// legacyVersion 未声明 version 时按此版本处理
+const legacyVersion = "1.0"
+
+// checkVersion 检查声明的配置版本
+// 不支持的版本返回错误；过时或未声明的版本记录为警告
+func checkVersion(cfg *Config) error {
+	declared := cfg.Version
+	if declared == "" {
+		cfg.warnings = append(cfg.warnings, cfg.source.newIssue(SeverityWarning, "version",
+			fmt.Sprintf("未声明配置版本，按 %s 处理（运行 xbuilder migrate 可声明版本并升级到 %s）", legacyVersion, CurrentVersion)))
+		return nil
+	}
+
+	if !slices.Contains(SupportedVersions, declared) {
+		if compareVersions(declared, CurrentVersion) > 0 {
+			return fmt.Errorf("配置版本 %s 高于当前支持的最高版本 %s，请升级 xbuilder", declared, CurrentVersion)
+		}
+		return fmt.Errorf("不支持的配置版本: %s (支持: %s)", declared, strings.Join(SupportedVersions, ", "))
+	}
+
+	if declared != CurrentVersion {
+		cfg.warnings = append(cfg.warnings, cfg.source.newIssue(SeverityWarning, "version",
+			fmt.Sprintf("配置版本 %s 已过时（当前 %s），运行 xbuilder migrate 可自动升级", declared, CurrentVersion)))
+	}
+	return nil
+}
+
+// compareVersions 比较 major.minor 形式的版本号，无法解析的部分按 0 处理
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
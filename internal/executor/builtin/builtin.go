@@ -0,0 +1,12 @@
+// Package builtin 注册所有内置任务类型
+// 使用方通过空白导入启用: import _ "github.com/xiaolfeng/builder-cli/internal/executor/builtin"
+package builtin
+
+import (
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/docker"
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/gobuild"
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/maven"
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/shell"
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/ssh"
+	_ "github.com/xiaolfeng/builder-cli/internal/executor/upload"
+)
@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tagPattern Docker 镜像标签格式
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// cacheTypes 支持的缓存后端及其必填属性（cache_from / cache_to 分别校验）
+var cacheTypes = map[string]struct {
+	from []string // --cache-from 必填属性
+	to   []string // --cache-to 必填属性
+}{
+	"registry": {from: []string{"ref"}, to: []string{"ref"}},
+	"local":    {from: []string{"src"}, to: []string{"dest"}},
+	"inline":   {},
+	"gha":      {},
+	"s3":       {from: []string{"bucket", "region"}, to: []string{"bucket", "region"}},
+	"azblob":   {from: []string{"name"}, to: []string{"name"}},
+}
+
+// BuildSecret 构建时挂载的 secret（--secret），src 与 env 二选一
+type BuildSecret struct {
+	ID  string `yaml:"id"`
+	Src string `yaml:"src,omitempty"` // 本地文件路径
+	Env string `yaml:"env,omitempty"` // 环境变量名
+}
+
+// Arg 返回 --secret 参数值
+func (s BuildSecret) Arg() string {
+	if s.Env != "" {
+		return fmt.Sprintf("id=%s,env=%s", s.ID, s.Env)
+	}
+	return fmt.Sprintf("id=%s,src=%s", s.ID, s.Src)
+}
+
+// parseCacheSpec 解析缓存描述，如 "type=registry,ref=repo/app:cache,mode=max"
+// 不含 = 的简写视为 registry 引用（与 docker buildx 一致）
+func parseCacheSpec(spec string) (map[string]string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("缓存描述不能为空")
+	}
+	if !strings.Contains(spec, "=") {
+		return map[string]string{"type": "registry", "ref": spec}, nil
+	}
+
+	attrs := make(map[string]string)
+	for _, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("无效的缓存属性 %q，应为 key=value", field)
+		}
+		if _, dup := attrs[key]; dup {
+			return nil, fmt.Errorf("缓存属性重复: %s", key)
+		}
+		attrs[key] = strings.TrimSpace(value)
+	}
+	if attrs["type"] == "" {
+		return nil, fmt.Errorf("缺少 type 属性")
+	}
+	return attrs, nil
+}
+
+// validateCacheSpec 校验 cache_from / cache_to 描述，export 为 true 时按 cache_to 校验
+func validateCacheSpec(spec string, export bool) error {
+	attrs, err := parseCacheSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	def, ok := cacheTypes[attrs["type"]]
+	if !ok {
+		return fmt.Errorf("不支持的缓存类型 %q (支持: registry, local, inline, gha, s3, azblob)", attrs["type"])
+	}
+
+	required := def.from
+	if export {
+		required = def.to
+	}
+	for _, key := range required {
+		if attrs[key] == "" {
+			return fmt.Errorf("type=%s 缺少必填属性 %s", attrs["type"], key)
+		}
+	}
+
+	if mode, ok := attrs["mode"]; ok {
+		if !export {
+			return fmt.Errorf("mode 仅适用于 cache_to")
+		}
+		if mode != "min" && mode != "max" {
+			return fmt.Errorf("无效的 mode %q (支持: min, max)", mode)
+		}
+	}
+	return nil
+}
+
+// needsBuildx 判断缓存描述是否需要 buildx（经典 docker build 仅支持 registry 镜像作为缓存源）
+func needsBuildx(cacheFrom, cacheTo []string) bool {
+	if len(cacheTo) > 0 {
+		return true
+	}
+	for _, spec := range cacheFrom {
+		if attrs, err := parseCacheSpec(spec); err == nil && attrs["type"] != "registry" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveTag 将额外标签解析为完整镜像名：纯标签拼接到 imageName，包含 : 或 / 时视为完整镜像名
+func resolveTag(imageName, tag string) string {
+	if strings.ContainsAny(tag, ":/") {
+		return tag
+	}
+	return fmt.Sprintf("%s:%s", imageName, tag)
+}
@@ -0,0 +1,303 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+// preflightTimeout 检查/创建 builder 的超时时间（首次 bootstrap 需要拉取 buildkit 镜像）
+const preflightTimeout = 5 * time.Minute
+
+// builderDrivers 可由 xbuilder 创建的 buildx 驱动（docker 驱动的 builder 由 docker 自带，无法创建）
+var builderDrivers = []string{"docker-container", "kubernetes", "remote"}
+
+// builderNamePattern buildx builder 名称格式
+var builderNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// BuilderConfig buildx builder 配置，不存在时自动创建
+type BuilderConfig struct {
+	Name       string            `yaml:"name"`
+	Driver     string            `yaml:"driver,omitempty"`      // 默认 docker-container
+	Config     string            `yaml:"config,omitempty"`      // buildkitd.toml 路径
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"` // --driver-opt，如 network: host
+}
+
+// driver 返回 builder 驱动
+func (b *BuilderConfig) driver() string {
+	if b.Driver == "" {
+		return "docker-container"
+	}
+	return b.Driver
+}
+
+// builderInfo docker buildx inspect 的结果
+type builderInfo struct {
+	Name      string
+	Driver    string
+	Platforms []string // 所有节点支持的平台
+}
+
+// preflight 构建前检查 builder：按需创建配置的 builder，并确认支持请求的平台、缓存导出与导出到文件
+func (e *DockerBuildExecutor) preflight(ctx context.Context, handler executor.OutputHandler) error {
+	cacheExports := exportedCaches(e.cacheTo)
+	if e.builder == nil && len(e.platforms) == 0 && len(cacheExports) == 0 && e.export == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, preflightTimeout)
+	defer cancel()
+
+	name := ""
+	if e.builder != nil {
+		name = e.builder.Name
+		ensure := func() error { return e.ensureBuilder(ctx, handler) }
+		if e.session == nil {
+			if err := ensure(); err != nil {
+				return err
+			}
+		} else if _, err := e.session.Once("buildx-builder:"+name, ensure); err != nil {
+			return err
+		}
+	}
+	if len(e.platforms) == 0 && len(cacheExports) == 0 && e.export == nil {
+		return nil
+	}
+
+	info, err := inspectBuilder(ctx, name)
+	if err != nil {
+		return err
+	}
+	if (len(cacheExports) > 0 || e.export != nil) && info.Driver == "docker" && !containerdImageStore(ctx) {
+		if e.export != nil {
+			return exportError(info, e.export.format(), e.builder == nil)
+		}
+		return cacheExportError(info, cacheExports, e.builder == nil)
+	}
+	if len(e.platforms) == 0 {
+		return nil
+	}
+	missing := unsupportedPlatforms(e.platforms, info.Platforms)
+	if len(missing) == 0 {
+		handler(fmt.Sprintf("✅ builder %s (%s) 支持平台: %s", info.Name, info.Driver, strings.Join(e.platforms, ", ")), false)
+		return nil
+	}
+	return platformError(info, missing, e.builder == nil)
+}
+
+// ensureBuilder 检查配置的 builder 是否存在，不存在时创建
+func (e *DockerBuildExecutor) ensureBuilder(ctx context.Context, handler executor.OutputHandler) error {
+	b := e.builder
+	names, err := listBuilders(ctx)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(names, b.Name) {
+		info, err := inspectBuilder(ctx, b.Name)
+		if err != nil {
+			return err
+		}
+		if info.Driver != b.driver() {
+			handler(fmt.Sprintf("⚠️  [WARN] 已存在的 builder %s 使用 %s 驱动（配置为 %s），继续使用；如需重建请执行 docker buildx rm %s",
+				b.Name, info.Driver, b.driver(), b.Name), false)
+		} else {
+			handler(fmt.Sprintf("🧱 使用 buildx builder: %s (%s)", b.Name, info.Driver), false)
+		}
+		return nil
+	}
+
+	handler(fmt.Sprintf("🧱 创建 buildx builder: %s (%s)", b.Name, b.driver()), false)
+	args := []string{"buildx", "create", "--name", b.Name, "--driver", b.driver()}
+	if b.Config != "" {
+		args = append(args, "--config", executor.ExpandHomePath(b.Config))
+	}
+	for _, k := range slices.Sorted(maps.Keys(b.DriverOpts)) {
+		args = append(args, "--driver-opt", fmt.Sprintf("%s=%s", k, b.DriverOpts[k]))
+	}
+	args = append(args, "--bootstrap")
+
+	runner := executor.NewCommandRunnerWithArgs(e.Name()+"-builder", "docker", args)
+	runner.SetWorkingDir(e.GetWorkingDir())
+	runner.SetTimeout(preflightTimeout)
+	if err := runner.Execute(ctx, handler); err != nil {
+		return fmt.Errorf("创建 buildx builder %s 失败: %w", b.Name, err)
+	}
+	return nil
+}
+
+// listBuilders 通过 docker buildx ls 列出已有的 builder 名称
+func listBuilders(ctx context.Context) ([]string, error) {
+	out, err := dockerOutput(ctx, "buildx", "ls")
+	if err != nil {
+		return nil, fmt.Errorf("docker buildx ls 失败（是否已安装 buildx 插件？）: %w", err)
+	}
+
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		// 节点行以空白或 \_ 开头，builder 行顶格；当前 builder 名称带 * 后缀
+		if line == "" || line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, `\_`) {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "NAME/NODE" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(fields[0], "*"))
+	}
+	return names, nil
+}
+
+// inspectBuilder 启动并查询 builder（name 为空时为当前 builder）的驱动与支持的平台
+func inspectBuilder(ctx context.Context, name string) (*builderInfo, error) {
+	args := []string{"buildx", "inspect", "--bootstrap"}
+	if name != "" {
+		args = append(args, name)
+	}
+	out, err := dockerOutput(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("docker buildx inspect 失败: %w", err)
+	}
+	return parseBuilderInfo(out), nil
+}
+
+// parseBuilderInfo 解析 docker buildx inspect 输出
+func parseBuilderInfo(out string) *builderInfo {
+	info := &builderInfo{}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Name":
+			// 第一个 Name 为 builder，之后为节点名称
+			if info.Name == "" {
+				info.Name = value
+			}
+		case "Driver":
+			info.Driver = value
+		case "Platforms":
+			for _, p := range strings.Split(value, ",") {
+				p = strings.TrimSuffix(strings.TrimSpace(p), "*")
+				if p != "" && !slices.Contains(info.Platforms, p) {
+					info.Platforms = append(info.Platforms, p)
+				}
+			}
+		}
+	}
+	return info
+}
+
+// unsupportedPlatforms 返回 builder 不支持的平台
+// linux/arm64 与 linux/arm64/v8 这类带或不带变体的写法视为同一平台
+func unsupportedPlatforms(requested, supported []string) []string {
+	var missing []string
+	for _, p := range requested {
+		ok := slices.ContainsFunc(supported, func(s string) bool {
+			return s == p || strings.HasPrefix(s, p+"/") || strings.HasPrefix(p, s+"/")
+		})
+		if !ok {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// platformError 生成平台不受支持时的错误说明
+func platformError(info *builderInfo, missing []string, defaultBuilder bool) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "builder %s (%s 驱动) 不支持平台: %s", info.Name, info.Driver, strings.Join(missing, ", "))
+	if len(info.Platforms) > 0 {
+		fmt.Fprintf(&b, "\n  支持的平台: %s", strings.Join(info.Platforms, ", "))
+	}
+
+	if info.Driver == "docker" {
+		b.WriteString("\n  docker 驱动只能构建本机平台，请使用 docker-container 驱动的 builder")
+		if defaultBuilder {
+			b.WriteString("，如在任务中配置 builder: {name: xbuilder}")
+		}
+	}
+
+	// 非本机架构需要 QEMU 模拟
+	var arches []string
+	for _, p := range missing {
+		parts := strings.Split(p, "/")
+		if len(parts) >= 2 && parts[1] != runtime.GOARCH && !slices.Contains(arches, parts[1]) {
+			arches = append(arches, parts[1])
+		}
+	}
+	if len(arches) > 0 {
+		fmt.Fprintf(&b, "\n  跨架构构建需要注册 QEMU binfmt: docker run --privileged --rm tonistiigi/binfmt --install %s", strings.Join(arches, ","))
+		if info.Driver != "docker" {
+			fmt.Fprintf(&b, "\n  注册后需重启 builder 才能识别新平台: docker buildx stop %s", info.Name)
+		}
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+// exportedCaches 返回需要 builder 导出的缓存描述（inline 缓存写入镜像，不需要导出）
+func exportedCaches(cacheTo []string) []string {
+	var specs []string
+	for _, spec := range cacheTo {
+		if attrs, err := parseCacheSpec(spec); err == nil && attrs["type"] != "inline" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// containerdImageStore 检查 docker 是否启用了 containerd 镜像存储（启用后 docker 驱动也支持导出缓存）
+func containerdImageStore(ctx context.Context) bool {
+	out, err := dockerOutput(ctx, "info", "--format", "{{json .DriverStatus}}")
+	return err == nil && strings.Contains(out, "io.containerd.snapshotter")
+}
+
+// cacheExportError 生成 docker 驱动不支持导出缓存时的错误说明
+func cacheExportError(info *builderInfo, specs []string, defaultBuilder bool) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "builder %s (docker 驱动) 不支持导出缓存: %s", info.Name, strings.Join(specs, "; "))
+	b.WriteString("\n  请使用 docker-container 驱动的 builder")
+	if defaultBuilder {
+		b.WriteString("（如在任务中配置 builder: {name: xbuilder}）")
+	}
+	b.WriteString("，或改用 cache_to: [\"type=inline\"]（缓存写入镜像），或为 docker 启用 containerd 镜像存储")
+	return fmt.Errorf("%s", b.String())
+}
+
+// exportError 生成 docker 驱动不支持导出到文件时的错误说明
+func exportError(info *builderInfo, format string, defaultBuilder bool) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "builder %s (docker 驱动) 不支持将镜像导出为 %s 格式的 tar 文件", info.Name, format)
+	b.WriteString("\n  请使用 docker-container 驱动的 builder")
+	if defaultBuilder {
+		b.WriteString("（如在任务中配置 builder: {name: xbuilder}）")
+	}
+	b.WriteString("，或为 docker 启用 containerd 镜像存储")
+	return fmt.Errorf("%s", b.String())
+}
+
+// dockerOutput 执行 docker 命令并返回 stdout
+func dockerOutput(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
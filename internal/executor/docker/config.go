@@ -0,0 +1,569 @@
+package docker
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+func init() {
+	executor.Register(executor.Definition{
+		Type:      executor.TypeDockerBuild,
+		NewConfig: func() config.TaskSettings { return &BuildConfig{} },
+		New: func(rt *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+			exec := NewDockerBuildExecutor(taskName, *settings.(*BuildConfig))
+			if rt.Config != nil {
+				exec.SetProjectName(rt.Config.Project.Name)
+			}
+			exec.SetSession(rt.Session)
+			return exec, nil
+		},
+		Expand: expandAutoScan,
+	})
+
+	executor.Register(executor.Definition{
+		Type:      executor.TypeDockerPush,
+		NewConfig: func() config.TaskSettings { return &PushConfig{} },
+		New:       newPushExecutor,
+	})
+
+	executor.Register(executor.Definition{
+		Type:      executor.TypeImageTransfer,
+		NewConfig: func() config.TaskSettings { return &TransferConfig{} },
+		New:       newTransferExecutor,
+	})
+}
+
+// BuildConfig Docker 构建任务配置
+type BuildConfig struct {
+	config.CommonConfig  `yaml:",inline"`
+	config.RefreshConfig `yaml:",inline"` // Docker 构建日志强制降级刷新（避免刷屏）
+
+	WorkingDir    string            `yaml:"working_dir,omitempty"`
+	Dockerfile    string            `yaml:"dockerfile,omitempty"`
+	Context       string            `yaml:"context,omitempty"`
+	ImageName     string            `yaml:"image_name,omitempty"`
+	Tag           string            `yaml:"tag,omitempty"`
+	BuildArgs     map[string]string `yaml:"build_args,omitempty"`
+	Platforms     []string          `yaml:"platforms,omitempty"`      // 多平台构建，如 ["linux/amd64", "linux/arm64"]
+	Tags          []string          `yaml:"tags,omitempty"`           // 额外标签，纯标签拼接到 image_name，也可写完整镜像名
+	TagStrategies []string          `yaml:"tag_strategies,omitempty"` // 按策略派生标签，如 ["semver", "git-sha", "latest-on-default-branch"]
+	PushOnBuild   *bool             `yaml:"push_on_build,omitempty"`  // 多平台构建时是否自动推送 (默认 true)
+	Builder       *BuilderConfig    `yaml:"builder,omitempty"`        // 使用（必要时创建）指定的 buildx builder
+	Export        *ExportConfig     `yaml:"export,omitempty"`         // 按平台导出到文件，由 docker-push 推送
+	Target        string            `yaml:"target,omitempty"`         // 多阶段构建的目标阶段
+	CacheFrom     []string          `yaml:"cache_from,omitempty"`     // 缓存来源，如 "type=registry,ref=repo/app:cache"
+	CacheTo       []string          `yaml:"cache_to,omitempty"`       // 缓存导出，如 "type=local,dest=.cache,mode=max"（使用 buildx）
+	Secrets       []BuildSecret     `yaml:"secrets,omitempty"`        // 构建 secret（--secret）
+	SSH           []string          `yaml:"ssh,omitempty"`            // 转发的 SSH agent/密钥，如 ["default"]
+	Labels        map[string]string `yaml:"labels,omitempty"`
+	OCILabels     *OCILabelsConfig  `yaml:"oci_labels,omitempty"` // 自动注入 OCI 标签（默认启用）
+	NoCache       bool              `yaml:"no_cache,omitempty"`
+	Pull          bool              `yaml:"pull,omitempty"` // 总是拉取最新的基础镜像
+	AutoScan      *AutoScanConfig   `yaml:"auto_scan,omitempty"`
+
+	// PushLatestOnBuild 已废弃（1.0 格式），由 xbuilder migrate 迁移为 tag_strategies
+	PushLatestOnBuild bool `yaml:"push_latest_on_build,omitempty" deprecated:"tag_strategies"`
+}
+
+// AutoScanConfig Dockerfile 自动扫描配置
+// pattern 与 exclude 使用 doublestar glob（** 匹配任意层级目录），相对于 working_dir 匹配
+type AutoScanConfig struct {
+	Enabled     bool                    `yaml:"enabled"`
+	Pattern     string                  `yaml:"pattern"`
+	Exclude     []string                `yaml:"exclude,omitempty"`
+	ImagePrefix string                  `yaml:"image_prefix,omitempty"`
+	Tag         string                  `yaml:"tag,omitempty"`
+	Platforms   []string                `yaml:"platforms,omitempty"` // 多平台构建，如 ["linux/amd64", "linux/arm64"]
+	Overrides   map[string]ScanOverride `yaml:"overrides,omitempty"` // 按 Dockerfile 路径或所在目录覆盖单个服务的配置
+}
+
+// ScanOverride 单个扫描结果的覆盖配置
+type ScanOverride struct {
+	ImageName string            `yaml:"image_name,omitempty"`
+	Tag       string            `yaml:"tag,omitempty"`
+	BuildArgs map[string]string `yaml:"build_args,omitempty"` // 与任务 build_args 合并，同名时覆盖
+	Platforms []string          `yaml:"platforms,omitempty"`
+}
+
+// Validate 验证 Docker 构建任务
+func (c *BuildConfig) Validate(v *config.TaskValidation) {
+	// 如果启用了自动扫描，则不需要其他配置
+	if c.AutoScan != nil && c.AutoScan.Enabled {
+		if c.AutoScan.Pattern == "" {
+			v.AddError("auto_scan.pattern", "自动扫描模式不能为空")
+		} else if !validGlob(c.AutoScan.Pattern) {
+			v.AddError("auto_scan.pattern", fmt.Sprintf("无效的 glob 模式: %s", c.AutoScan.Pattern))
+		}
+		for i, exclude := range c.AutoScan.Exclude {
+			if !validGlob(exclude) {
+				v.AddError(fmt.Sprintf("auto_scan.exclude[%d]", i), fmt.Sprintf("无效的 glob 模式: %s", exclude))
+			}
+		}
+		c.validateOptions(v)
+		return
+	}
+
+	if c.Dockerfile == "" {
+		v.AddError("dockerfile", "Dockerfile 路径不能为空")
+	}
+	if c.ImageName == "" {
+		v.AddError("image_name", "镜像名称不能为空")
+	}
+	c.validateOptions(v)
+}
+
+// validateOptions 验证缓存、secret、标签等构建选项
+func (c *BuildConfig) validateOptions(v *config.TaskValidation) {
+	for i, spec := range c.CacheFrom {
+		if err := validateCacheSpec(spec, false); err != nil {
+			v.AddError(fmt.Sprintf("cache_from[%d]", i), fmt.Sprintf("无效的缓存描述 %q: %v", spec, err))
+		}
+	}
+	for i, spec := range c.CacheTo {
+		if err := validateCacheSpec(spec, true); err != nil {
+			v.AddError(fmt.Sprintf("cache_to[%d]", i), fmt.Sprintf("无效的缓存描述 %q: %v", spec, err))
+		}
+	}
+
+	ids := make(map[string]bool)
+	for i, secret := range c.Secrets {
+		field := fmt.Sprintf("secrets[%d]", i)
+		switch {
+		case secret.ID == "":
+			v.AddError(field+".id", "secret id 不能为空")
+		case ids[secret.ID]:
+			v.AddError(field+".id", fmt.Sprintf("secret id 重复: %s", secret.ID))
+		}
+		ids[secret.ID] = true
+		if (secret.Src == "") == (secret.Env == "") {
+			v.AddError(field, "src 与 env 必须且只能指定一个")
+		}
+	}
+
+	for i, s := range c.SSH {
+		if strings.TrimSpace(s) == "" {
+			v.AddError(fmt.Sprintf("ssh[%d]", i), "ssh 不能为空（转发默认 agent 请使用 default）")
+		}
+	}
+
+	if c.OCILabels != nil {
+		for _, key := range slices.Sorted(maps.Keys(c.OCILabels.Templates)) {
+			if err := checkLabelTemplate(key, c.OCILabels.Templates[key]); err != nil {
+				v.AddError("oci_labels.templates."+key, fmt.Sprintf("无效的标签模板: %v", err))
+			}
+		}
+	}
+
+	if b := c.Builder; b != nil {
+		switch {
+		case b.Name == "":
+			v.AddError("builder.name", "builder 名称不能为空")
+		case !builderNamePattern.MatchString(b.Name):
+			v.AddError("builder.name", fmt.Sprintf("无效的 builder 名称: %s", b.Name))
+		}
+		if b.Driver != "" && !slices.Contains(builderDrivers, b.Driver) {
+			v.AddError("builder.driver", fmt.Sprintf("不支持的 builder 驱动: %s (可选: %s)", b.Driver, strings.Join(builderDrivers, ", ")))
+		}
+	}
+
+	if x := c.Export; x != nil {
+		if x.Type != "" && !slices.Contains(exportFormats, x.Type) {
+			v.AddError("export.type", fmt.Sprintf("不支持的导出格式: %s (可选: %s)", x.Type, strings.Join(exportFormats, ", ")))
+		}
+		if x.Path == "" {
+			v.AddError("export.path", "导出目录不能为空")
+		}
+		if c.PushOnBuild != nil && *c.PushOnBuild {
+			v.AddError("export", "export 与 push_on_build: true 不能同时使用（导出的镜像由 docker-push 推送）")
+		}
+	}
+
+	c.validateTags(v)
+	validateStrategies(c.TagStrategies, "tag_strategies", v.AddError)
+}
+
+// validateTags 验证额外标签
+func (c *BuildConfig) validateTags(v *config.TaskValidation) {
+	autoScan := c.AutoScan != nil && c.AutoScan.Enabled
+	for i, tag := range c.Tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		switch {
+		case !strings.ContainsAny(tag, ":/"):
+			if !tagPattern.MatchString(tag) {
+				v.AddError(field, fmt.Sprintf("无效的镜像标签: %s", tag))
+			}
+		case autoScan:
+			// 完整镜像名会被所有扫描到的服务共用
+			v.AddError(field, fmt.Sprintf("启用 auto_scan 时 tags 只能是纯标签: %s", tag))
+		}
+	}
+}
+
+// Expand 展开变量引用
+func (c *BuildConfig) Expand(expand func(string) string) {
+	c.WorkingDir = expand(c.WorkingDir)
+	c.Dockerfile = expand(c.Dockerfile)
+	c.Context = expand(c.Context)
+	c.ImageName = expand(c.ImageName)
+	c.Tag = expand(c.Tag)
+	for k, val := range c.BuildArgs {
+		c.BuildArgs[k] = expand(val)
+	}
+	for i, p := range c.Platforms {
+		c.Platforms[i] = expand(p)
+	}
+	c.Target = expand(c.Target)
+	if c.Export != nil {
+		c.Export.Path = expand(c.Export.Path)
+	}
+	if c.Builder != nil {
+		c.Builder.Name = expand(c.Builder.Name)
+		c.Builder.Config = expand(c.Builder.Config)
+		for k, val := range c.Builder.DriverOpts {
+			c.Builder.DriverOpts[k] = expand(val)
+		}
+	}
+	for i, spec := range c.CacheFrom {
+		c.CacheFrom[i] = expand(spec)
+	}
+	for i, spec := range c.CacheTo {
+		c.CacheTo[i] = expand(spec)
+	}
+	for i, secret := range c.Secrets {
+		c.Secrets[i].ID = expand(secret.ID)
+		c.Secrets[i].Src = expand(secret.Src)
+		c.Secrets[i].Env = expand(secret.Env)
+	}
+	for i, s := range c.SSH {
+		c.SSH[i] = expand(s)
+	}
+	for k, val := range c.Labels {
+		c.Labels[k] = expand(val)
+	}
+	if c.OCILabels != nil {
+		for k, val := range c.OCILabels.Templates {
+			c.OCILabels.Templates[k] = expand(val)
+		}
+	}
+	for i, tag := range c.Tags {
+		c.Tags[i] = expand(tag)
+	}
+	for i, strategy := range c.TagStrategies {
+		c.TagStrategies[i] = expand(strategy)
+	}
+	if c.AutoScan != nil {
+		c.AutoScan.Pattern = expand(c.AutoScan.Pattern)
+		c.AutoScan.ImagePrefix = expand(c.AutoScan.ImagePrefix)
+		c.AutoScan.Tag = expand(c.AutoScan.Tag)
+		for i, exclude := range c.AutoScan.Exclude {
+			c.AutoScan.Exclude[i] = expand(exclude)
+		}
+		for i, p := range c.AutoScan.Platforms {
+			c.AutoScan.Platforms[i] = expand(p)
+		}
+		for key, o := range c.AutoScan.Overrides {
+			o.ImageName = expand(o.ImageName)
+			o.Tag = expand(o.Tag)
+			for k, val := range o.BuildArgs {
+				o.BuildArgs[k] = expand(val)
+			}
+			for i, p := range o.Platforms {
+				o.Platforms[i] = expand(p)
+			}
+			c.AutoScan.Overrides[key] = o
+		}
+	}
+}
+
+// clone 深拷贝配置，展开的子任务各自持有独立的切片和映射（执行前会原地替换运行时变量）
+func (c *BuildConfig) clone() *BuildConfig {
+	out := *c
+	out.BuildArgs = maps.Clone(c.BuildArgs)
+	out.Labels = maps.Clone(c.Labels)
+	out.Platforms = slices.Clone(c.Platforms)
+	out.CacheFrom = slices.Clone(c.CacheFrom)
+	out.CacheTo = slices.Clone(c.CacheTo)
+	out.Secrets = slices.Clone(c.Secrets)
+	out.SSH = slices.Clone(c.SSH)
+	out.Tags = slices.Clone(c.Tags)
+	out.TagStrategies = slices.Clone(c.TagStrategies)
+	if c.Export != nil {
+		export := *c.Export
+		out.Export = &export
+	}
+	if c.Builder != nil {
+		builder := *c.Builder
+		builder.DriverOpts = maps.Clone(c.Builder.DriverOpts)
+		out.Builder = &builder
+	}
+	if c.OCILabels != nil {
+		oci := *c.OCILabels
+		oci.Templates = maps.Clone(c.OCILabels.Templates)
+		out.OCILabels = &oci
+	}
+	return &out
+}
+
+// PushConfig Docker 推送任务配置
+type PushConfig struct {
+	config.CommonConfig `yaml:",inline"`
+
+	Registry           string   `yaml:"registry,omitempty"`
+	Registries         []string `yaml:"registries,omitempty"` // 推送到多个 Registry，镜像按各 Registry 的 url 重新打标签
+	Images             []string `yaml:"images,omitempty"`
+	Auto               bool     `yaml:"auto,omitempty"`
+	TagStrategies      []string `yaml:"tag_strategies,omitempty"`       // 推送时按策略派生标签（见 docker-build 的 tag_strategies）
+	AllowMirrorFailure bool     `yaml:"allow_mirror_failure,omitempty"` // registries 中第一个之后的镜像仓库推送失败时不中断任务
+
+	// PushLatest 已废弃（1.0 格式），由 xbuilder migrate 迁移为 tag_strategies: [latest]
+	PushLatest bool `yaml:"push_latest,omitempty" deprecated:"tag_strategies"`
+}
+
+// Validate 验证 Docker 推送任务
+func (c *PushConfig) Validate(v *config.TaskValidation) {
+	switch {
+	case c.Registry != "" && len(c.Registries) > 0:
+		v.AddError("registries", "registry 与 registries 不能同时设置")
+	case len(c.Registries) > 0:
+		seen := make(map[string]bool)
+		for i, name := range c.Registries {
+			field := fmt.Sprintf("registries[%d]", i)
+			if _, ok := v.Config().Registries[name]; !ok {
+				v.AddError(field, fmt.Sprintf("Registry 不存在: %s", name))
+			} else if seen[name] {
+				v.AddError(field, fmt.Sprintf("Registry 重复: %s", name))
+			}
+			seen[name] = true
+		}
+	case c.Registry == "":
+		v.AddError("registry", "Registry 名称不能为空")
+	default:
+		if _, ok := v.Config().Registries[c.Registry]; !ok {
+			v.AddError("registry", fmt.Sprintf("Registry 不存在: %s", c.Registry))
+		}
+	}
+	if c.AllowMirrorFailure && len(c.Registries) < 2 {
+		v.AddError("allow_mirror_failure", "allow_mirror_failure 仅在 registries 包含多个 Registry 时有效")
+	}
+
+	if len(c.Images) == 0 && !c.Auto {
+		v.AddError("", "必须指定 images 列表或设置 auto: true")
+	}
+	validateStrategies(c.TagStrategies, "tag_strategies", v.AddError)
+}
+
+// Expand 展开变量引用
+func (c *PushConfig) Expand(expand func(string) string) {
+	c.Registry = expand(c.Registry)
+	for i, name := range c.Registries {
+		c.Registries[i] = expand(name)
+	}
+	for i, image := range c.Images {
+		c.Images[i] = expand(image)
+	}
+	for i, strategy := range c.TagStrategies {
+		c.TagStrategies[i] = expand(strategy)
+	}
+}
+
+// newPushExecutor 创建推送执行器，auto 模式下推送此前构建的所有镜像
+func newPushExecutor(rt *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+	cfg := *settings.(*PushConfig)
+
+	// 单个 registry 按镜像原名推送；registries 按各 Registry 的 url 重新打标签
+	names, retag := []string{cfg.Registry}, false
+	if len(cfg.Registries) > 0 {
+		names, retag = cfg.Registries, true
+	}
+
+	targets := make([]PushTarget, 0, len(names))
+	for _, name := range names {
+		reg, ok := rt.Config.Registries[name]
+		if !ok {
+			return nil, fmt.Errorf("Registry 不存在: %s", name)
+		}
+		targets = append(targets, PushTarget{Name: name, Registry: &reg, Retag: retag})
+	}
+
+	exec := NewDockerPushExecutor(taskName, cfg, targets)
+	exec.SetSession(rt.Session)
+	exec.SetExports(rt.Exports)
+	if cfg.Auto {
+		exec.SetImages(rt.BuiltImages)
+		exec.SetSkipPushedImages(rt.PushedImages)
+	}
+	return exec, nil
+}
+
+// expandAutoScan 启用 auto_scan 时，为每个扫描到的 Dockerfile 生成一个构建子任务
+// 子任务继承父任务的通用配置（timeout、force_refresh、build_args、推送选项等）
+func expandAutoScan(taskName string, settings config.TaskSettings) ([]executor.SubTask, error) {
+	cfg := settings.(*BuildConfig)
+	if cfg.AutoScan == nil || !cfg.AutoScan.Enabled {
+		return nil, nil
+	}
+
+	// 未在 auto_scan 中指定的标签和平台沿用任务配置
+	scanCfg := *cfg.AutoScan
+	if scanCfg.Tag == "" {
+		scanCfg.Tag = cfg.Tag
+	}
+	if len(scanCfg.Platforms) == 0 {
+		scanCfg.Platforms = cfg.Platforms
+	}
+
+	scanned, err := NewDockerScanner(cfg.WorkingDir, &scanCfg).Scan()
+	if err != nil {
+		return nil, fmt.Errorf("扫描 Dockerfile 失败: %w", err)
+	}
+	if len(scanned) == 0 {
+		return nil, fmt.Errorf("自动扫描未发现 Dockerfile (pattern: %s)", scanCfg.Pattern)
+	}
+
+	subTasks := make([]executor.SubTask, 0, len(scanned))
+	used := make(map[string]bool)
+	for _, df := range scanned {
+		sub := cfg.clone()
+		sub.AutoScan = nil
+		sub.Dockerfile = df.Dockerfile
+		sub.Context = df.Context
+		sub.ImageName = df.ImageName
+		sub.Tag = df.Tag
+		sub.Platforms = slices.Clone(df.Platforms)
+
+		if df.Override != "" {
+			used[df.Override] = true
+			if sub.BuildArgs == nil {
+				sub.BuildArgs = make(map[string]string, len(df.BuildArgs))
+			}
+			maps.Copy(sub.BuildArgs, df.BuildArgs)
+		}
+
+		subTasks = append(subTasks, executor.SubTask{
+			Name:     fmt.Sprintf("%s (%s)", taskName, df.Name),
+			Settings: sub,
+		})
+	}
+
+	// overrides 中未命中的路径多半是拼写错误，直接报错
+	keys := slices.Sorted(maps.Keys(scanCfg.Overrides))
+	for _, key := range keys {
+		if used[key] {
+			continue
+		}
+		// 目录下的 Dockerfile 都已按路径单独覆盖，或与另一个写法不同的键指向同一路径
+		for _, other := range keys {
+			if used[other] && (overrideKey(other) == overrideKey(key) || path.Dir(overrideKey(other)) == overrideKey(key)) {
+				return nil, fmt.Errorf("auto_scan.overrides 中的 %s 未生效：已被 %s 覆盖（Dockerfile 路径优先于所在目录，同一路径只能配置一次）", key, other)
+			}
+		}
+		return nil, fmt.Errorf("auto_scan.overrides 中的路径未匹配任何扫描到的 Dockerfile: %s", key)
+	}
+	return subTasks, nil
+}
+
+// TransferConfig 镜像传输任务配置（docker save 经 SSH 传输到服务器后 docker load，无需 Registry）
+type TransferConfig struct {
+	config.CommonConfig `yaml:",inline"`
+
+	Server   string   `yaml:"server,omitempty"`
+	Servers  []string `yaml:"servers,omitempty"` // 依次传输到多个服务器
+	Images   []string `yaml:"images,omitempty"`
+	Auto     bool     `yaml:"auto,omitempty"`     // 传输此前任务构建的所有镜像
+	Compress string   `yaml:"compress,omitempty"` // 传输压缩: none(默认) | gzip | zstd
+}
+
+// transferCompressions 支持的传输压缩方式
+var transferCompressions = []string{"none", "gzip", "zstd"}
+
+// TargetServers 返回任务连接的服务器
+func (c *TransferConfig) TargetServers() []string {
+	if c.Server != "" {
+		return append([]string{c.Server}, c.Servers...)
+	}
+	return c.Servers
+}
+
+// Validate 验证镜像传输任务
+func (c *TransferConfig) Validate(v *config.TaskValidation) {
+	switch {
+	case c.Server != "" && len(c.Servers) > 0:
+		v.AddError("servers", "server 与 servers 不能同时设置")
+	case len(c.Servers) > 0:
+		seen := make(map[string]bool)
+		for i, name := range c.Servers {
+			field := fmt.Sprintf("servers[%d]", i)
+			if _, ok := v.Config().Servers[name]; !ok {
+				v.AddError(field, fmt.Sprintf("服务器不存在: %s", name))
+			} else if seen[name] {
+				v.AddError(field, fmt.Sprintf("服务器重复: %s", name))
+			}
+			seen[name] = true
+		}
+	case c.Server == "":
+		v.AddError("server", "服务器名称不能为空")
+	default:
+		if _, ok := v.Config().Servers[c.Server]; !ok {
+			v.AddError("server", fmt.Sprintf("服务器不存在: %s", c.Server))
+		}
+	}
+
+	if len(c.Images) == 0 && !c.Auto {
+		v.AddError("", "必须指定 images 列表或设置 auto: true")
+	}
+	if c.Compress != "" && !slices.Contains(transferCompressions, c.Compress) {
+		v.AddError("compress",
+			fmt.Sprintf("不支持的压缩方式: %s (支持: %s)", c.Compress, strings.Join(transferCompressions, ", ")))
+	}
+}
+
+// Expand 展开变量引用
+func (c *TransferConfig) Expand(expand func(string) string) {
+	c.Server = expand(c.Server)
+	for i, name := range c.Servers {
+		c.Servers[i] = expand(name)
+	}
+	for i, image := range c.Images {
+		c.Images[i] = expand(image)
+	}
+	c.Compress = expand(c.Compress)
+}
+
+// newTransferExecutor 创建镜像传输执行器，auto 模式下传输此前构建的所有镜像
+func newTransferExecutor(rt *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+	cfg := *settings.(*TransferConfig)
+
+	// 通过 --server 限制目标服务器时只传输到其中的服务器
+	names := rt.Config.TaskServers(&cfg)
+	targets := make([]TransferTarget, 0, len(names))
+	for _, name := range names {
+		server, ok := rt.Config.Servers[name]
+		if !ok {
+			return nil, fmt.Errorf("服务器不存在: %s", name)
+		}
+		jumps, err := rt.Config.Route(name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, TransferTarget{Name: name, Server: server, Jumps: jumps})
+	}
+
+	exec, err := NewImageTransferExecutor(taskName, cfg, targets)
+	if err != nil {
+		return nil, err
+	}
+	exec.SetExports(rt.Exports)
+	exec.SetPrompt(rt.Prompt)
+	exec.SetProgress(rt.Progress)
+	exec.SetPool(rt.SSHPool)
+	if cfg.Auto {
+		exec.SetImages(rt.BuiltImages)
+	}
+	return exec, nil
+}
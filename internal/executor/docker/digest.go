@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+var (
+	// docker push 输出: "1.0: digest: sha256:... size: 1234"
+	pushDigestPattern = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+	// buildx --push 输出: "pushing manifest for repo/app:1.0@sha256:..."
+	buildxDigestPattern = regexp.MustCompile(`pushing manifest for (\S+)@(sha256:[0-9a-f]{64})`)
+)
+
+// digestCapture 在转发输出的同时从中提取镜像摘要
+type digestCapture struct {
+	mu      sync.Mutex
+	digests map[string]string // 镜像名称（buildx 输出中的完整引用）→ 摘要
+	last    string            // 最近一次出现的摘要
+}
+
+// newDigestCapture 创建摘要提取器
+func newDigestCapture() *digestCapture {
+	return &digestCapture{digests: make(map[string]string)}
+}
+
+// wrap 包装输出处理器
+func (c *digestCapture) wrap(handler executor.OutputHandler) executor.OutputHandler {
+	return func(line string, isError bool) {
+		c.mu.Lock()
+		if m := buildxDigestPattern.FindStringSubmatch(line); m != nil {
+			c.digests[m[1]] = m[2]
+			c.last = m[2]
+		} else if m := pushDigestPattern.FindStringSubmatch(line); m != nil {
+			c.last = m[1]
+		}
+		c.mu.Unlock()
+		handler(line, isError)
+	}
+}
+
+// lookup 返回镜像的摘要，buildx 输出中的引用可能带有 docker.io/ 前缀
+func (c *digestCapture) lookup(image string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ref, digest := range c.digests {
+		if ref == image || strings.HasSuffix(ref, "/"+image) {
+			return digest
+		}
+	}
+	return ""
+}
+
+// reset 清除最近一次的摘要（docker push 每次推送前调用）
+func (c *digestCapture) reset() {
+	c.mu.Lock()
+	c.last = ""
+	c.mu.Unlock()
+}
+
+// latest 返回最近一次出现的摘要
+func (c *digestCapture) latest() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// inspectDigest 通过 docker buildx imagetools inspect 查询 Registry 中镜像的摘要
+func inspectDigest(ctx context.Context, image string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "buildx", "imagetools", "inspect", "--format", "{{json .Manifest}}", image)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("查询镜像摘要失败: %s", msg)
+		}
+		return "", fmt.Errorf("查询镜像摘要失败: %w", err)
+	}
+
+	var manifest struct {
+		Digest string `json:"digest"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &manifest); err != nil {
+		return "", fmt.Errorf("解析镜像摘要失败: %w", err)
+	}
+	if manifest.Digest == "" {
+		return "", fmt.Errorf("未获取到镜像摘要: %s", image)
+	}
+	return manifest.Digest, nil
+}
+
+// resolveDigest 优先使用输出中解析到的摘要，否则查询 Registry
+func resolveDigest(ctx context.Context, image, parsed string, handler executor.OutputHandler) string {
+	if parsed != "" {
+		return parsed
+	}
+	digest, err := inspectDigest(ctx, image)
+	if err != nil {
+		handler(fmt.Sprintf("⚠️  [WARN] %v", err), false)
+		return ""
+	}
+	return digest
+}
+
+// registryHost 返回镜像名称中的 Registry 地址（Docker Hub 镜像返回 docker.io）
+func registryHost(image string) string {
+	host, _, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		return host
+	}
+	return "docker.io"
+}
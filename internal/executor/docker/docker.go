@@ -0,0 +1,692 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+// DockerBuildExecutor Docker 构建执行器
+type DockerBuildExecutor struct {
+	*executor.BaseExecutor
+	dockerfile    string
+	context       string
+	imageName     string
+	tag           string
+	buildArgs     map[string]string
+	platforms     []string // 多平台支持
+	pushOnBuild   bool     // 多平台构建时是否自动推送
+	tags          []string // 额外标签（纯标签或完整镜像名）
+	tagStrategies []string // 标签策略
+	imageNames    []string // 解析后的全部镜像名称（执行时确定）
+	builder       *BuilderConfig
+	export        *ExportConfig
+	exports       []executor.ImageExport
+	session       *executor.Session // 运行会话（同一 builder 只检查/创建一次）
+	target        string
+	cacheFrom     []string
+	cacheTo       []string
+	secrets       []BuildSecret
+	ssh           []string
+	labels        map[string]string
+	noCache       bool
+	pull          bool
+	ociLabels     *OCILabelsConfig
+	projectName   string
+	appliedLabels map[string]string // 实际写入镜像的标签
+	pushedImages  []executor.PushedImage
+	pushed        bool // 记录镜像是否已推送
+}
+
+// NewDockerBuildExecutor 创建 Docker 构建执行器
+func NewDockerBuildExecutor(taskName string, cfg BuildConfig) *DockerBuildExecutor {
+	e := &DockerBuildExecutor{
+		BaseExecutor:  executor.NewBaseExecutor(taskName, executor.TypeDockerBuild),
+		dockerfile:    cfg.Dockerfile,
+		context:       cfg.Context,
+		imageName:     cfg.ImageName,
+		tag:           cfg.Tag,
+		buildArgs:     cfg.BuildArgs,
+		platforms:     cfg.Platforms,
+		pushOnBuild:   true, // 默认 true（保持向后兼容）
+		tags:          slices.Clone(cfg.Tags),
+		tagStrategies: normalizeStrategies(cfg.TagStrategies, cfg.PushLatestOnBuild),
+		builder:       cfg.Builder,
+		export:        cfg.Export,
+		target:        cfg.Target,
+		cacheFrom:     cfg.CacheFrom,
+		cacheTo:       cfg.CacheTo,
+		secrets:       cfg.Secrets,
+		ssh:           cfg.SSH,
+		labels:        cfg.Labels,
+		noCache:       cfg.NoCache,
+		pull:          cfg.Pull,
+		ociLabels:     cfg.OCILabels,
+		pushed:        false,
+	}
+
+	// 如果配置中明确指定了 push_on_build
+	if cfg.PushOnBuild != nil {
+		e.pushOnBuild = *cfg.PushOnBuild
+	}
+
+	// 工作目录（用于解析相对路径）
+	if cfg.WorkingDir != "" {
+		e.SetWorkingDir(cfg.WorkingDir)
+	}
+
+	// 默认值
+	if e.context == "" {
+		e.context = "."
+	}
+	if e.tag == "" {
+		e.tag = "latest"
+	}
+
+	// 设置超时
+	if cfg.Timeout > 0 {
+		e.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
+	} else {
+		e.SetTimeout(30 * time.Minute) // Docker 构建默认 30 分钟
+	}
+
+	return e
+}
+
+// SetProjectName 设置项目名称（用于 org.opencontainers.image.title 标签）
+func (e *DockerBuildExecutor) SetProjectName(name string) {
+	e.projectName = name
+}
+
+// SetSession 设置运行会话
+func (e *DockerBuildExecutor) SetSession(session *executor.Session) {
+	e.session = session
+}
+
+// FullImageName 返回完整的镜像名称
+func (e *DockerBuildExecutor) FullImageName() string {
+	return fmt.Sprintf("%s:%s", e.imageName, e.tag)
+}
+
+// ImageNames 返回构建产生的所有镜像名称（主标签在前，随后是 tags 与按策略派生的标签）
+// 执行前调用时不包含策略派生的标签
+func (e *DockerBuildExecutor) ImageNames() []string {
+	if e.imageNames != nil {
+		return e.imageNames
+	}
+	return e.staticImageNames()
+}
+
+// staticImageNames 返回主标签与 tags 对应的镜像名称
+func (e *DockerBuildExecutor) staticImageNames() []string {
+	names := []string{e.FullImageName()}
+	for _, tag := range e.tags {
+		if name := resolveTag(e.imageName, tag); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// resolveImageNames 计算全部镜像名称，策略派生的标签拼接到 image_name
+func (e *DockerBuildExecutor) resolveImageNames(handler executor.OutputHandler) []string {
+	names := e.staticImageNames()
+	if len(e.tagStrategies) == 0 {
+		return names
+	}
+
+	derived, notes := newTagResolver(e.tagStrategies, e.GetWorkingDir(), handler).derive(e.tag)
+	for _, note := range notes {
+		handler(note, false)
+	}
+	for _, tag := range derived {
+		if name := resolveTag(e.imageName, tag); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsPushed 返回镜像是否已在构建阶段推送
+func (e *DockerBuildExecutor) IsPushed() bool {
+	return e.pushed
+}
+
+// Execute 执行 Docker 构建
+func (e *DockerBuildExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("🐳 构建 Docker 镜像: %s", e.FullImageName()), false)
+	handler(fmt.Sprintf("📄 Dockerfile: %s", e.dockerfile), false)
+	handler(fmt.Sprintf("📁 Context: %s", e.context), false)
+	if e.target != "" {
+		handler(fmt.Sprintf("🎯 Target: %s", e.target), false)
+	}
+	if len(e.platforms) > 0 {
+		handler(fmt.Sprintf("🖥️  Platforms: %s", strings.Join(e.platforms, ", ")), false)
+	}
+	e.imageNames = e.resolveImageNames(handler)
+	if len(e.imageNames) > 1 {
+		handler(fmt.Sprintf("🏷️  额外标签: %s", strings.Join(e.imageNames[1:], ", ")), false)
+	}
+	labels, err := e.resolveLabels(handler)
+	if err != nil {
+		return err
+	}
+	e.appliedLabels = labels
+
+	// 检查 builder 与平台支持，避免构建数分钟后才失败
+	if err := e.preflight(ctx, handler); err != nil {
+		return err
+	}
+	handler("", false)
+
+	if e.export != nil {
+		return e.exportBuild(ctx, handler)
+	}
+
+	capture := newDigestCapture()
+	if err := e.run(ctx, e.buildArgv(handler), capture.wrap(handler)); err != nil {
+		return err
+	}
+
+	if e.pushed {
+		e.recordPushed(ctx, capture, handler)
+	}
+	return nil
+}
+
+// run 在工作目录中执行 docker 构建命令
+func (e *DockerBuildExecutor) run(ctx context.Context, args []string, handler executor.OutputHandler) error {
+	runner := executor.NewCommandRunnerWithArgs(e.Name(), "docker", args)
+	runner.SetWorkingDir(e.GetWorkingDir())
+	runner.SetTimeout(e.GetTimeout())
+	env := e.GetEnv()
+	if len(e.secrets) > 0 || len(e.ssh) > 0 {
+		// --secret / --ssh 依赖 BuildKit
+		env = append(env, "DOCKER_BUILDKIT=1")
+	}
+	runner.SetEnv(env)
+	return runner.Execute(ctx, handler)
+}
+
+// recordPushed 记录构建阶段推送的镜像及其摘要（所有标签指向同一个 manifest）
+func (e *DockerBuildExecutor) recordPushed(ctx context.Context, capture *digestCapture, handler executor.OutputHandler) {
+	digest := resolveDigest(ctx, e.FullImageName(), capture.lookup(e.FullImageName()), handler)
+	if digest != "" {
+		handler(fmt.Sprintf("🔖 镜像摘要: %s", digest), false)
+	}
+	for _, name := range e.ImageNames() {
+		e.pushedImages = append(e.pushedImages, executor.PushedImage{
+			Image:    name,
+			Digest:   digest,
+			Registry: registryHost(name),
+		})
+	}
+}
+
+// PushedImages 返回构建阶段推送的镜像
+func (e *DockerBuildExecutor) PushedImages() []executor.PushedImage {
+	return e.pushedImages
+}
+
+// buildArgv 生成 docker 命令参数
+// 多平台构建、指定 builder 或导出缓存时使用 buildx，否则使用 docker build
+func (e *DockerBuildExecutor) buildArgv(handler executor.OutputHandler) []string {
+	multiPlatform := len(e.platforms) > 0
+	args := []string{"build"}
+
+	if multiPlatform {
+		args = []string{"buildx", "build"}
+
+		// 根据配置决定是否在构建时推送
+		if e.pushOnBuild {
+			args = append(args, "--push")
+			e.pushed = true
+			handler("ℹ️  [INFO] 多平台构建将直接推送镜像", false)
+		} else {
+			// 不推送，但镜像也不会存在于本地（buildx 限制）
+			args = append(args, "--output", "type=image,push=false")
+			handler("⚠️  [WARN] 多平台构建未启用推送，镜像不会保存到本地（可配置 export 导出到文件）", false)
+		}
+	} else if e.builder != nil || needsBuildx(e.cacheFrom, e.cacheTo) {
+		// 单平台使用 buildx 时需要 --load 才能将镜像保存到本地
+		args = []string{"buildx", "build", "--load"}
+	}
+
+	if e.builder != nil {
+		args = append(args, "--builder", e.builder.Name)
+	}
+
+	// 镜像标签
+	for _, name := range e.ImageNames() {
+		args = append(args, "-t", name)
+	}
+	args = append(args, e.buildFlags()...)
+
+	// 平台列表
+	if multiPlatform {
+		args = append(args, "--platform", strings.Join(e.platforms, ","))
+	}
+
+	// Context
+	return append(args, e.context)
+}
+
+// buildFlags 返回构建与导出共用的参数（Dockerfile、构建参数、标签、缓存、secret 等）
+func (e *DockerBuildExecutor) buildFlags() []string {
+	var args []string
+	if e.dockerfile != "" {
+		args = append(args, "-f", e.dockerfile)
+	}
+	if e.target != "" {
+		args = append(args, "--target", e.target)
+	}
+
+	// 构建参数与标签（排序保证命令稳定）
+	for _, k := range slices.Sorted(maps.Keys(e.buildArgs)) {
+		args = append(args, "--build-arg", fmt.Sprintf("%s=%s", k, e.buildArgs[k]))
+	}
+	for _, k := range slices.Sorted(maps.Keys(e.appliedLabels)) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, e.appliedLabels[k]))
+	}
+
+	for _, spec := range e.cacheFrom {
+		args = append(args, "--cache-from", spec)
+	}
+	for _, spec := range e.cacheTo {
+		args = append(args, "--cache-to", spec)
+	}
+	for _, secret := range e.secrets {
+		// 不经过 shell 执行，需要自行展开 ~
+		secret.Src = executor.ExpandHomePath(secret.Src)
+		args = append(args, "--secret", secret.Arg())
+	}
+	for _, s := range e.ssh {
+		args = append(args, "--ssh", s)
+	}
+	if e.noCache {
+		args = append(args, "--no-cache")
+	}
+	if e.pull {
+		args = append(args, "--pull")
+	}
+	return args
+}
+
+// resolveLabels 计算写入镜像的标签（OCI 默认标签 + 模板 + labels）
+func (e *DockerBuildExecutor) resolveLabels(handler executor.OutputHandler) (map[string]string, error) {
+	if !e.ociLabels.enabled() {
+		return maps.Clone(e.labels), nil
+	}
+
+	gitDir := e.GetWorkingDir()
+	if gitDir == "" {
+		gitDir = "."
+	}
+	data, err := newLabelData(e.projectName, e.Name(), e.imageName, e.tag, gitDir)
+	if err != nil {
+		handler(fmt.Sprintf("ℹ️  [INFO] 未检测到 git 仓库，跳过 revision/source 标签: %v", err), false)
+	} else if data.Git.Dirty {
+		handler(fmt.Sprintf("⚠️  [WARN] 工作区存在未提交的修改，revision 标签 (%s) 与镜像内容可能不一致", data.Git.ShortCommit), false)
+	}
+
+	labels, err := buildLabels(e.ociLabels, e.labels, data)
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// InspectImage 查询构建出的本地镜像 ID 与标签
+// 多平台构建或导出到文件的镜像不会加载到本地，此时只返回写入的标签
+func (e *DockerBuildExecutor) InspectImage(ctx context.Context) (*executor.ImageDetails, error) {
+	if len(e.platforms) > 0 || e.export != nil {
+		return &executor.ImageDetails{Labels: maps.Clone(e.appliedLabels), Platforms: e.platforms}, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{json .}}", e.FullImageName())
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("docker image inspect 失败: %s", msg)
+		}
+		return nil, fmt.Errorf("docker image inspect 失败: %w", err)
+	}
+
+	var inspect struct {
+		ID           string `json:"Id"`
+		Os           string `json:"Os"`
+		Architecture string `json:"Architecture"`
+		Variant      string `json:"Variant"`
+		Config       struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &inspect); err != nil {
+		return nil, fmt.Errorf("解析 docker image inspect 输出失败: %w", err)
+	}
+
+	details := &executor.ImageDetails{ID: inspect.ID, Labels: inspect.Config.Labels}
+	if inspect.Os != "" && inspect.Architecture != "" {
+		platform := inspect.Os + "/" + inspect.Architecture
+		if inspect.Variant != "" {
+			platform += "/" + inspect.Variant
+		}
+		details.Platforms = []string{platform}
+	}
+	return details, nil
+}
+
+// DockerPushExecutor Docker 推送执行器
+type DockerPushExecutor struct {
+	*executor.BaseExecutor
+	targets            []PushTarget
+	images             []string
+	tagStrategies      []string                          // 标签策略
+	resolver           *tagResolver                      // 标签解析器（执行时创建）
+	noted              map[string]bool                   // 已输出的标签策略说明
+	allowMirrorFailure bool                              // 镜像仓库（第一个之后的目标）推送失败时不中断任务
+	skipPushed         map[string]bool                   // 需要跳过的已推送镜像
+	exports            map[string][]executor.ImageExport // 已导出到文件的镜像
+	loaded             map[string]bool                   // 已加载的导出文件
+	pushed             []executor.PushedImage
+	session            *executor.Session // 运行会话（记录已登录的 Registry）
+}
+
+// PushTarget 推送目标 Registry
+type PushTarget struct {
+	Name     string
+	Registry *config.Registry
+	Retag    bool // 是否按 Registry URL 重新标记镜像后推送
+}
+
+// pushReport 单个 Registry 的推送结果
+type pushReport struct {
+	target PushTarget
+	pushed []string
+	err    error
+}
+
+// NewDockerPushExecutor 创建 Docker 推送执行器，第一个目标为主仓库，其余为镜像仓库
+func NewDockerPushExecutor(taskName string, cfg PushConfig, targets []PushTarget) *DockerPushExecutor {
+	e := &DockerPushExecutor{
+		BaseExecutor:       executor.NewBaseExecutor(taskName, executor.TypeDockerPush),
+		targets:            targets,
+		images:             cfg.Images,
+		tagStrategies:      normalizeStrategies(cfg.TagStrategies, cfg.PushLatest),
+		allowMirrorFailure: cfg.AllowMirrorFailure,
+		skipPushed:         nil,
+	}
+
+	// 设置超时
+	if cfg.Timeout > 0 {
+		e.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
+	} else {
+		e.SetTimeout(20 * time.Minute) // 推送默认 20 分钟
+	}
+
+	return e
+}
+
+// SetSession 设置运行会话
+func (e *DockerPushExecutor) SetSession(session *executor.Session) {
+	e.session = session
+}
+
+// SetImages 设置要推送的镜像列表
+func (e *DockerPushExecutor) SetImages(images []string) {
+	e.images = images
+}
+
+// SetSkipPushedImages 设置已推送的镜像（这些将被跳过）
+func (e *DockerPushExecutor) SetSkipPushedImages(pushed map[string]bool) {
+	e.skipPushed = pushed
+}
+
+// Execute 执行 Docker 推送
+func (e *DockerPushExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
+	capture := newDigestCapture()
+	handler = capture.wrap(handler)
+
+	e.loaded = make(map[string]bool)
+	if len(e.tagStrategies) > 0 {
+		e.resolver = newTagResolver(e.tagStrategies, e.GetWorkingDir(), handler)
+		e.resolver.lenient = true
+		e.noted = make(map[string]bool)
+	}
+
+	reports := make([]pushReport, 0, len(e.targets))
+	for i, target := range e.targets {
+		if len(e.targets) > 1 {
+			handler(fmt.Sprintf("📦 [%d/%d] 推送到 Registry: %s (%s)", i+1, len(e.targets), target.Name, target.Registry.URL), false)
+		}
+
+		report := e.pushTo(ctx, target, capture, handler)
+		reports = append(reports, report)
+		if report.err == nil {
+			continue
+		}
+
+		// 主仓库失败或未允许镜像仓库失败时中断
+		if i == 0 || !e.allowMirrorFailure {
+			e.printReport(reports, handler)
+			return report.err
+		}
+		handler(fmt.Sprintf("⚠️  [WARN] 镜像仓库 %s 推送失败（已设置 allow_mirror_failure，继续执行）: %v", target.Name, report.err), false)
+		handler("", false)
+	}
+
+	e.printReport(reports, handler)
+	return nil
+}
+
+// pushTo 推送所有镜像到单个 Registry
+func (e *DockerPushExecutor) pushTo(ctx context.Context, target PushTarget, capture *digestCapture, handler executor.OutputHandler) pushReport {
+	report := pushReport{target: target}
+
+	// 登录 Registry
+	release, err := registryLogin(ctx, target.Registry, e.session, handler)
+	if err != nil {
+		report.err = fmt.Errorf("Registry 登录失败 [%s]: %w", target.Name, err)
+		return report
+	}
+	defer release()
+
+	// 推送每个镜像及其派生标签（多个镜像可能派生出同一个标签，如 latest，只推送一次）
+	done := make(map[string]bool)
+	for _, image := range e.images {
+		repo, tag := splitTag(image)
+		names := []string{image}
+		for _, t := range e.deriveTags(tag, handler) {
+			names = append(names, repo+":"+t)
+		}
+
+		// 导出到文件的镜像先加载到本地；单平台导出的文件中只有主标签
+		source := image
+		exports := e.exports[image]
+		if len(exports) > 0 {
+			if err := e.loadExports(ctx, exports, handler); err != nil {
+				report.err = err
+				return report
+			}
+			if !multiPlatformExport(exports) {
+				source = exports[0].Image
+			}
+		}
+
+		var primary string // 本镜像在目标 Registry 中的名称
+		for i, name := range names {
+			ref := name
+			if target.Retag {
+				ref = retagImage(name, target.Registry.URL)
+			}
+			if i == 0 {
+				primary = ref
+			}
+			if done[ref] {
+				continue
+			}
+			done[ref] = true
+
+			// 检查是否已在构建阶段推送（跳过）
+			if e.skipPushed[name] && ref == name {
+				handler(fmt.Sprintf("⏭️  [SKIP] 镜像已在构建阶段推送，跳过: %s", name), false)
+				continue
+			}
+
+			var err error
+			switch {
+			case multiPlatformExport(exports) && i == 0:
+				err = e.pushExport(ctx, target, ref, exports, capture, handler)
+			case multiPlatformExport(exports):
+				// 派生标签直接复制已合并的多平台 manifest
+				err = e.copyRemote(ctx, target, primary, ref, capture, handler)
+			default:
+				err = e.publish(ctx, target, source, ref, capture, handler)
+			}
+			if err != nil {
+				report.err = err
+				return report
+			}
+			report.pushed = append(report.pushed, ref)
+		}
+		handler("", false)
+	}
+
+	return report
+}
+
+// deriveTags 按标签策略派生标签，跳过策略的说明每次执行只输出一次
+func (e *DockerPushExecutor) deriveTags(tag string, handler executor.OutputHandler) []string {
+	if e.resolver == nil {
+		return nil
+	}
+	tags, notes := e.resolver.derive(tag)
+	for _, note := range notes {
+		if !e.noted[note] {
+			e.noted[note] = true
+			handler(note, false)
+		}
+	}
+	return tags
+}
+
+// publish 将 source 发布为 ref：构建阶段已推送的镜像在 Registry 之间复制，本地镜像标记后推送
+func (e *DockerPushExecutor) publish(ctx context.Context, target PushTarget, source, ref string, capture *digestCapture, handler executor.OutputHandler) error {
+	if e.skipPushed[source] {
+		// 多平台镜像未加载到本地，直接在 Registry 之间复制
+		return e.copyRemote(ctx, target, source, ref, capture, handler)
+	}
+	if ref != source {
+		handler(fmt.Sprintf("🏷️  标记镜像: %s → %s", source, ref), false)
+		if err := e.tag(ctx, source, ref, handler); err != nil {
+			return err
+		}
+	}
+	return e.push(ctx, target, ref, capture, handler)
+}
+
+// tag 为本地镜像添加新标签
+func (e *DockerPushExecutor) tag(ctx context.Context, source, target string, handler executor.OutputHandler) error {
+	runner := executor.NewCommandRunnerWithArgs(e.Name()+"-tag", "docker", []string{"tag", source, target})
+	runner.SetTimeout(30 * time.Second)
+	if err := runner.Execute(ctx, handler); err != nil {
+		return fmt.Errorf("标记镜像失败 [%s]: %w", target, err)
+	}
+	return nil
+}
+
+// push 推送单个镜像并记录摘要
+func (e *DockerPushExecutor) push(ctx context.Context, target PushTarget, image string, capture *digestCapture, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("📤 推送镜像: %s", image), false)
+
+	capture.reset()
+	runner := executor.NewCommandRunnerWithArgs(e.Name(), "docker", []string{"push", image})
+	runner.SetTimeout(e.GetTimeout())
+	if err := runner.Execute(ctx, handler); err != nil {
+		return fmt.Errorf("推送镜像失败 [%s]: %w", image, err)
+	}
+
+	handler(fmt.Sprintf("✅ 镜像推送成功: %s", image), false)
+	e.recordPushed(ctx, target, image, capture.latest(), handler)
+	return nil
+}
+
+// copyRemote 使用 docker buildx imagetools create 在 Registry 之间复制镜像（保留多平台 manifest）
+func (e *DockerPushExecutor) copyRemote(ctx context.Context, target PushTarget, source, ref string, capture *digestCapture, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("📋 复制镜像: %s → %s", source, ref), false)
+
+	capture.reset()
+	runner := executor.NewCommandRunnerWithArgs(e.Name(), "docker", []string{"buildx", "imagetools", "create", "--tag", ref, source})
+	runner.SetTimeout(e.GetTimeout())
+	if err := runner.Execute(ctx, handler); err != nil {
+		return fmt.Errorf("复制镜像失败 [%s]: %w", ref, err)
+	}
+
+	handler(fmt.Sprintf("✅ 镜像复制成功: %s", ref), false)
+	e.recordPushed(ctx, target, ref, "", handler)
+	return nil
+}
+
+// printReport 输出各 Registry 的推送结果
+func (e *DockerPushExecutor) printReport(reports []pushReport, handler executor.OutputHandler) {
+	if len(e.targets) < 2 {
+		return
+	}
+
+	handler("📊 推送结果:", false)
+	for _, r := range reports {
+		if r.err != nil {
+			handler(fmt.Sprintf("   ❌ %s (%s): %v", r.target.Name, r.target.Registry.URL, r.err), false)
+			continue
+		}
+		handler(fmt.Sprintf("   ✅ %s (%s): %d 个镜像", r.target.Name, r.target.Registry.URL, len(r.pushed)), false)
+	}
+	for _, t := range e.targets[len(reports):] {
+		handler(fmt.Sprintf("   ⏭️  %s (%s): 未执行", t.Name, t.Registry.URL), false)
+	}
+}
+
+// recordPushed 记录推送结果，输出中未解析到摘要时查询 Registry
+func (e *DockerPushExecutor) recordPushed(ctx context.Context, target PushTarget, image, parsed string, handler executor.OutputHandler) {
+	digest := resolveDigest(ctx, image, parsed, handler)
+	if digest != "" {
+		handler(fmt.Sprintf("🔖 镜像摘要: %s", digest), false)
+	}
+
+	registry := registryHost(image)
+	if target.Registry != nil && target.Registry.URL != "" {
+		registry = target.Registry.URL
+	}
+	e.pushed = append(e.pushed, executor.PushedImage{Image: image, Digest: digest, Registry: registry})
+}
+
+// PushedImages 返回本次推送的镜像（主仓库在前）
+func (e *DockerPushExecutor) PushedImages() []executor.PushedImage {
+	return e.pushed
+}
+
+// retagImage 将镜像重新标记到目标 Registry：url（可含命名空间）替换镜像原有的 Registry 地址，保留仓库路径及标签
+// 已位于该 Registry 下的镜像保持不变
+func retagImage(image, url string) string {
+	prefix := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://"), "/")
+	if prefix == "" || strings.HasPrefix(image, prefix+"/") {
+		return image
+	}
+	// 第一段含 . 或 : 或为 localhost 时是 Registry 地址（与 registryHost 的判断一致）
+	path := image
+	if host, rest, found := strings.Cut(image, "/"); found && (strings.ContainsAny(host, ".:") || host == "localhost") {
+		path = rest
+	}
+	return prefix + "/" + path
+}
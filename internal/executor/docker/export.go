@@ -0,0 +1,210 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+// exportFormats 支持的导出格式
+var exportFormats = []string{"docker", "oci"}
+
+// ExportConfig 镜像导出配置：按平台将镜像写入 path 目录下的 tar 文件（不加载到本地，也不推送）
+type ExportConfig struct {
+	Type string `yaml:"type,omitempty"` // docker（默认，可直接 docker load）或 oci（docker load 需要 Docker 25+）
+	Path string `yaml:"path"`           // 导出目录，相对于 working_dir
+}
+
+// format 返回导出格式
+func (c *ExportConfig) format() string {
+	if c.Type == "" {
+		return "docker"
+	}
+	return c.Type
+}
+
+// exportBuild 按平台逐个构建并导出到文件，平台之间共享同一 builder 的构建缓存
+// 多平台时文件中的镜像名称带平台后缀（如 app:1.0-linux-arm64），推送时再合并为多平台 manifest
+func (e *DockerBuildExecutor) exportBuild(ctx context.Context, handler executor.OutputHandler) error {
+	dir := executor.ExpandHomePath(e.export.Path)
+	if !filepath.IsAbs(dir) && e.GetWorkingDir() != "" {
+		dir = filepath.Join(e.GetWorkingDir(), dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("解析导出目录失败: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建导出目录失败: %w", err)
+	}
+
+	platforms := e.platforms
+	if len(platforms) == 0 {
+		platforms = []string{""}
+	}
+	for i, platform := range platforms {
+		name := e.FullImageName()
+		if platform != "" {
+			name = e.imageName + ":" + platformTag(e.tag, platform)
+		}
+		path := filepath.Join(dir, exportFileName(name))
+
+		if platform != "" {
+			handler(fmt.Sprintf("💾 [%d/%d] 导出 %s (%s): %s", i+1, len(platforms), name, platform, path), false)
+		} else {
+			handler(fmt.Sprintf("💾 导出 %s: %s", name, path), false)
+		}
+		if err := e.run(ctx, e.exportArgv(platform, path, name), handler); err != nil {
+			return fmt.Errorf("导出镜像失败 [%s]: %w", name, err)
+		}
+		e.exports = append(e.exports, executor.ImageExport{
+			Image:    name,
+			Platform: platform,
+			Path:     path,
+			Format:   e.export.format(),
+		})
+		handler("", false)
+	}
+
+	handler(fmt.Sprintf("✅ 已导出 %d 个文件到 %s（docker-push 可从导出文件推送）", len(e.exports), dir), false)
+	return nil
+}
+
+// exportArgv 生成导出单个平台的 docker buildx build 参数
+func (e *DockerBuildExecutor) exportArgv(platform, path, name string) []string {
+	args := []string{"buildx", "build", "--output", fmt.Sprintf("type=%s,dest=%s,name=%s", e.export.format(), path, name)}
+	if e.builder != nil {
+		args = append(args, "--builder", e.builder.Name)
+	}
+	args = append(args, e.buildFlags()...)
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	return append(args, e.context)
+}
+
+// ExportedImages 返回导出到文件的镜像
+func (e *DockerBuildExecutor) ExportedImages() []executor.ImageExport {
+	return e.exports
+}
+
+// platformTag 返回带平台后缀的标签，如 1.0 + linux/arm/v7 → 1.0-linux-arm-v7
+func platformTag(tag, platform string) string {
+	return sanitizeTag(tag + "-" + strings.ReplaceAll(platform, "/", "-"))
+}
+
+// exportFileName 返回导出文件名，如 registry.example.com/app:1.0-linux-amd64 → app_1.0-linux-amd64.tar
+func exportFileName(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	return strings.ReplaceAll(name, ":", "_") + ".tar"
+}
+
+// SetExports 设置已导出到文件的镜像
+func (e *DockerPushExecutor) SetExports(exports map[string][]executor.ImageExport) {
+	e.exports = exports
+}
+
+// loadExports 将导出文件加载到本地 docker（每个文件只加载一次）
+func (e *DockerPushExecutor) loadExports(ctx context.Context, exports []executor.ImageExport, handler executor.OutputHandler) error {
+	for _, x := range exports {
+		if e.loaded[x.Path] {
+			continue
+		}
+		if err := loadExportFile(ctx, e.Name(), e.GetTimeout(), x, handler); err != nil {
+			return err
+		}
+		e.loaded[x.Path] = true
+	}
+	return nil
+}
+
+// ociLoadMinVersion docker load 支持 OCI 格式导出文件的最低 Docker 主版本
+const ociLoadMinVersion = 25
+
+// loadExportFile 使用 docker load 将导出文件加载到本地 docker
+func loadExportFile(ctx context.Context, taskName string, timeout time.Duration, x executor.ImageExport, handler executor.OutputHandler) error {
+	if x.Format == "oci" {
+		if err := checkOCILoad(ctx); err != nil {
+			return fmt.Errorf("加载导出文件失败 [%s]: %w", x.Path, err)
+		}
+	}
+
+	handler(fmt.Sprintf("📥 加载导出文件: %s", x.Path), false)
+	runner := executor.NewCommandRunnerWithArgs(taskName+"-load", "docker", []string{"load", "-i", x.Path})
+	runner.SetTimeout(timeout)
+	if err := runner.Execute(ctx, handler); err != nil {
+		return fmt.Errorf("加载导出文件失败 [%s]: %w", x.Path, err)
+	}
+	return nil
+}
+
+// checkOCILoad 检查本地 Docker 能否 docker load OCI 格式的导出文件（Docker 25 起支持）
+func checkOCILoad(ctx context.Context) error {
+	out, err := dockerOutput(ctx, "version", "--format", "{{.Server.Version}}")
+	if err != nil {
+		return fmt.Errorf("查询 Docker 版本失败: %w", err)
+	}
+	version := strings.TrimSpace(out)
+	major, _, _ := strings.Cut(version, ".")
+	if n, err := strconv.Atoi(major); err == nil && n < ociLoadMinVersion {
+		return fmt.Errorf("Docker %s 不支持 docker load 加载 OCI 格式的导出文件（需要 %d.0 及以上），请升级 Docker 或使用 export.type: docker", version, ociLoadMinVersion)
+	}
+	return nil
+}
+
+// pushExport 推送多平台导出：各平台镜像依次以 ref 推送并记录摘要，再使用 imagetools create 按摘要合并为多平台 manifest 写入 ref
+// 各平台镜像只以摘要保留在 Registry 中，不会留下带平台后缀的标签
+func (e *DockerPushExecutor) pushExport(ctx context.Context, target PushTarget, ref string, exports []executor.ImageExport, capture *digestCapture, handler executor.OutputHandler) error {
+	repo, _ := splitTag(ref)
+	sources := make([]string, 0, len(exports))
+	for _, x := range exports {
+		if err := e.tag(ctx, x.Image, ref, handler); err != nil {
+			return err
+		}
+		handler(fmt.Sprintf("📤 推送平台镜像 (%s): %s → %s", x.Platform, x.Image, ref), false)
+		capture.reset()
+		runner := executor.NewCommandRunnerWithArgs(e.Name(), "docker", []string{"push", ref})
+		runner.SetTimeout(e.GetTimeout())
+		if err := runner.Execute(ctx, handler); err != nil {
+			return fmt.Errorf("推送镜像失败 [%s (%s)]: %w", ref, x.Platform, err)
+		}
+		digest := resolveDigest(ctx, ref, capture.latest(), handler)
+		if digest == "" {
+			return fmt.Errorf("未获取到平台镜像的摘要，无法合并多平台 manifest [%s (%s)]", ref, x.Platform)
+		}
+		sources = append(sources, repo+"@"+digest)
+	}
+	// 本地的 ref 标签指向最后推送的单个平台镜像，移除以免误用
+	e.untag(ctx, ref, handler)
+
+	handler(fmt.Sprintf("🧩 合并多平台 manifest: %s", ref), false)
+	capture.reset()
+	args := append([]string{"buildx", "imagetools", "create", "--tag", ref}, sources...)
+	runner := executor.NewCommandRunnerWithArgs(e.Name(), "docker", args)
+	runner.SetTimeout(e.GetTimeout())
+	if err := runner.Execute(ctx, handler); err != nil {
+		return fmt.Errorf("创建多平台 manifest 失败 [%s]: %w", ref, err)
+	}
+
+	handler(fmt.Sprintf("✅ 镜像推送成功: %s", ref), false)
+	e.recordPushed(ctx, target, ref, "", handler)
+	return nil
+}
+
+// untag 移除本地镜像标签（镜像仍被其他标签引用，不会删除镜像），失败时只输出警告
+func (e *DockerPushExecutor) untag(ctx context.Context, image string, handler executor.OutputHandler) {
+	if _, err := dockerOutput(ctx, "image", "rm", image); err != nil {
+		handler(fmt.Sprintf("⚠️  [WARN] 移除本地标签失败 [%s]: %v", image, err), false)
+	}
+}
+
+// multiPlatformExport 返回导出是否为多平台（需要推送后合并 manifest）
+func multiPlatformExport(exports []executor.ImageExport) bool {
+	return len(exports) > 0 && exports[0].Platform != ""
+}
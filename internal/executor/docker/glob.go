@@ -0,0 +1,66 @@
+package docker
+
+import (
+	"path"
+	"strings"
+)
+
+// matchGlob 以 doublestar 语义匹配路径（均使用 / 分隔）
+// ** 匹配零个或多个目录层级，其余段使用 path.Match 语义（*、?、[...]）
+func matchGlob(pattern, name string) bool {
+	return matchSegments(splitSegments(pattern), splitSegments(name))
+}
+
+// matchSegments 逐段匹配
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// 合并连续的 **
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// splitSegments 拆分路径段，忽略首尾和重复的 /
+func splitSegments(p string) []string {
+	var segs []string
+	for _, seg := range strings.Split(p, "/") {
+		if seg != "" && seg != "." {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
+
+// validGlob 检查 glob 模式语法
+func validGlob(pattern string) bool {
+	for _, seg := range splitSegments(pattern) {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"text/template"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/gitinfo"
+)
+
+// OCI 镜像标准标签
+const (
+	labelRevision = "org.opencontainers.image.revision"
+	labelSource   = "org.opencontainers.image.source"
+	labelCreated  = "org.opencontainers.image.created"
+	labelVersion  = "org.opencontainers.image.version"
+	labelTitle    = "org.opencontainers.image.title"
+)
+
+// OCILabelsConfig 自动注入的 org.opencontainers.image.* 标签配置
+type OCILabelsConfig struct {
+	Enabled   *bool             `yaml:"enabled,omitempty"`   // 默认 true
+	Templates map[string]string `yaml:"templates,omitempty"` // 自定义标签模板（text/template），可覆盖默认标签，值为空时移除该标签
+}
+
+// enabled 返回是否注入 OCI 标签（未配置时默认启用）
+func (c *OCILabelsConfig) enabled() bool {
+	return c == nil || c.Enabled == nil || *c.Enabled
+}
+
+// LabelData 标签模板可用的数据
+type LabelData struct {
+	Project string       // 项目名称
+	Task    string       // 任务名称
+	Image   string       // 镜像名称（不含标签）
+	Tag     string       // 镜像标签
+	Created string       // 构建时间（RFC 3339，UTC）
+	Git     gitinfo.Info // git 信息，不在仓库中时为空
+}
+
+// parseLabelTemplate 解析标签模板，引用不存在的字段时报错
+func parseLabelTemplate(key, text string) (*template.Template, error) {
+	return template.New(key).Option("missingkey=error").Parse(text)
+}
+
+// checkLabelTemplate 校验标签模板语法及引用的字段
+func checkLabelTemplate(key, text string) error {
+	tmpl, err := parseLabelTemplate(key, text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(io.Discard, LabelData{})
+}
+
+// defaultOCILabels 返回默认的 OCI 标签，值为空的标签不输出
+func defaultOCILabels(data LabelData) map[string]string {
+	title := data.Project
+	if title == "" {
+		title = data.Task
+	}
+
+	labels := make(map[string]string)
+	for key, value := range map[string]string{
+		labelRevision: data.Git.Commit,
+		labelSource:   data.Git.RemoteURL,
+		labelCreated:  data.Created,
+		labelVersion:  data.Tag,
+		labelTitle:    title,
+	} {
+		if value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// buildLabels 合并镜像标签，优先级: labels > oci_labels.templates > 默认 OCI 标签
+func buildLabels(cfg *OCILabelsConfig, explicit map[string]string, data LabelData) (map[string]string, error) {
+	if !cfg.enabled() {
+		return maps.Clone(explicit), nil
+	}
+
+	labels := defaultOCILabels(data)
+	if cfg != nil {
+		for key, text := range cfg.Templates {
+			tmpl, err := parseLabelTemplate(key, text)
+			if err != nil {
+				return nil, fmt.Errorf("标签模板 %s 解析失败: %w", key, err)
+			}
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				return nil, fmt.Errorf("标签模板 %s 渲染失败: %w", key, err)
+			}
+			if buf.Len() == 0 {
+				delete(labels, key)
+				continue
+			}
+			labels[key] = buf.String()
+		}
+	}
+	maps.Copy(labels, explicit)
+	return labels, nil
+}
+
+// newLabelData 收集标签模板数据，gitDir 不在 git 仓库中时 Git 为空
+func newLabelData(project, task, image, tag, gitDir string) (LabelData, error) {
+	data := LabelData{
+		Project: project,
+		Task:    task,
+		Image:   image,
+		Tag:     tag,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}
+	info, err := gitinfo.Detect(gitDir)
+	if err != nil {
+		return data, err
+	}
+	data.Git = *info
+	return data, nil
+}
@@ -0,0 +1,182 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+// loginTimeout 登录/登出超时时间
+const loginTimeout = 30 * time.Second
+
+// registryLogin 按 Registry 配置登录，同一次运行中每个 Registry 只登录一次
+// 推送结束后须调用返回的 release：设置了 logout_after 时由最后一个使用该 Registry 的任务登出，
+// 避免并行的推送任务登出其他任务仍在使用的凭据
+func registryLogin(ctx context.Context, reg *config.Registry, session *executor.Session, handler executor.OutputHandler) (func(), error) {
+	if reg.UseDockerConfig {
+		if dockerConfigHasAuth(reg.URL) {
+			handler(fmt.Sprintf("🔐 使用 docker 配置中已有的登录: %s", reg.URL), false)
+		} else {
+			handler(fmt.Sprintf("⚠️  [WARN] docker 配置中未找到 %s 的登录信息，推送可能失败", reg.URL), false)
+		}
+		return func() {}, nil
+	}
+
+	// 未配置凭据时直接使用 docker 当前的登录状态
+	hasCredentials := reg.CredentialHelper != "" || reg.Username != ""
+	login := func() error {
+		if !hasCredentials {
+			return nil
+		}
+		username, password := reg.Username, reg.Password
+		if reg.CredentialHelper != "" {
+			handler(fmt.Sprintf("🔑 通过 docker-credential-%s 获取凭据", reg.CredentialHelper), false)
+			var err error
+			username, password, err = helperCredentials(ctx, reg.CredentialHelper, loginKey(reg.URL))
+			if err != nil {
+				return err
+			}
+		}
+		return dockerLogin(ctx, loginKey(reg.URL), username, password, handler)
+	}
+
+	var logout func()
+	if reg.LogoutAfter {
+		// 任务被取消时也要登出
+		logout = func() { registryLogout(context.WithoutCancel(ctx), reg, handler) }
+	}
+
+	if session == nil {
+		if err := login(); err != nil {
+			return nil, err
+		}
+		if logout == nil {
+			return func() {}, nil
+		}
+		return logout, nil
+	}
+	ran, err := session.Acquire(loginKey(reg.URL), login)
+	if err != nil {
+		return nil, err
+	}
+	if !ran && hasCredentials {
+		handler(fmt.Sprintf("🔐 本次运行已登录 Registry，跳过: %s", reg.URL), false)
+	}
+	return func() { session.Release(loginKey(reg.URL), logout) }, nil
+}
+
+// registryLogout 登出 Registry
+func registryLogout(ctx context.Context, reg *config.Registry, handler executor.OutputHandler) {
+	handler(fmt.Sprintf("🔓 登出 Registry: %s", reg.URL), false)
+
+	runner := executor.NewCommandRunnerWithArgs("docker-logout", "docker", []string{"logout", loginKey(reg.URL)})
+	runner.SetTimeout(loginTimeout)
+	if err := runner.Execute(ctx, handler); err != nil {
+		handler(fmt.Sprintf("⚠️  [WARN] 登出失败: %v", err), false)
+	}
+}
+
+// dockerLogin 执行 docker login，密码通过 stdin 传递（不经过 shell，也不出现在进程参数中）
+func dockerLogin(ctx context.Context, host, username, password string, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("🔐 登录 Registry: %s", host), false)
+
+	args := []string{"login", "--username", username, "--password-stdin"}
+	if host != "" {
+		args = append(args, host)
+	}
+	runner := executor.NewCommandRunnerWithArgs("docker-login", "docker", args)
+	runner.SetTimeout(loginTimeout)
+	runner.SetStdin(strings.NewReader(password))
+	return runner.Execute(ctx, handler)
+}
+
+// helperCredentials 通过 docker-credential-<helper> get 获取 Registry 凭据
+func helperCredentials(ctx context.Context, helper, url string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(url)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stdout.String() + stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", "", fmt.Errorf("docker-credential-%s 获取凭据失败: %s", helper, msg)
+	}
+
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return "", "", fmt.Errorf("解析 docker-credential-%s 输出失败: %w", helper, err)
+	}
+	if creds.Username == "<token>" {
+		// identity token 无法用于 docker login，应交给 docker 自行调用 helper
+		return "", "", fmt.Errorf("docker-credential-%s 返回的是 identity token，请在 docker 配置的 credHelpers 中配置该 helper 并使用 use_docker_config: true", helper)
+	}
+	if creds.Username == "" || creds.Secret == "" {
+		return "", "", fmt.Errorf("docker-credential-%s 未返回 %s 的凭据", helper, url)
+	}
+	return creds.Username, creds.Secret, nil
+}
+
+// dockerConfigHasAuth 检查 docker 配置（$DOCKER_CONFIG 或 ~/.docker/config.json）中是否有 Registry 的登录信息
+func dockerConfigHasAuth(url string) bool {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return false
+	}
+	var cfg struct {
+		Auths       map[string]json.RawMessage `json:"auths"`
+		CredHelpers map[string]string          `json:"credHelpers"`
+		CredsStore  string                     `json:"credsStore"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return false
+	}
+
+	host := loginKey(url)
+	if _, ok := cfg.CredHelpers[host]; ok {
+		return true
+	}
+	for key := range cfg.Auths {
+		if loginKey(key) == host {
+			return true
+		}
+	}
+	// credsStore 中的凭据无法在不调用 helper 的情况下确认，视为存在
+	return cfg.CredsStore != ""
+}
+
+// loginKey 规范化 Registry 地址（去除协议和路径），作为登录地址和登录记录的键
+func loginKey(url string) string {
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	host, _, _ := strings.Cut(url, "/")
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		return "docker.io"
+	}
+	return host
+}
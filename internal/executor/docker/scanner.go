@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"io/fs"
+	"maps"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DockerScanner Dockerfile 扫描器
+// pattern 与 exclude 使用 doublestar 语义，相对于扫描根目录匹配
+type DockerScanner struct {
+	rootDir     string
+	pattern     string
+	exclude     []string
+	imagePrefix string
+	tag         string
+	platforms   []string // 多平台构建支持
+	overrides   map[string]ScanOverride
+}
+
+// ScannedDockerfile 扫描到的 Dockerfile
+type ScannedDockerfile struct {
+	Name       string            // 服务名（Dockerfile 所在目录名）
+	Dockerfile string            // Dockerfile 路径（相对于扫描根目录）
+	Context    string            // 构建上下文（Dockerfile 所在目录，相对于扫描根目录）
+	ImageName  string            // 镜像名称（image_prefix + 目录名，可被 overrides 覆盖）
+	Tag        string            // 镜像标签
+	BuildArgs  map[string]string // overrides 中追加的构建参数
+	Platforms  []string          // 多平台构建
+	Override   string            // 命中的 overrides 键（未命中为空）
+}
+
+// NewDockerScanner 创建 Dockerfile 扫描器
+func NewDockerScanner(rootDir string, cfg *AutoScanConfig) *DockerScanner {
+	s := &DockerScanner{
+		rootDir:     rootDir,
+		pattern:     cfg.Pattern,
+		exclude:     cfg.Exclude,
+		imagePrefix: cfg.ImagePrefix,
+		tag:         cfg.Tag,
+		platforms:   cfg.Platforms,
+		overrides:   cfg.Overrides,
+	}
+
+	if s.rootDir == "" {
+		s.rootDir = "."
+	}
+	if s.pattern == "" {
+		s.pattern = "**/Dockerfile"
+	}
+	if s.tag == "" {
+		s.tag = "latest"
+	}
+
+	return s
+}
+
+// Scan 扫描 Dockerfile，结果按路径排序
+func (s *DockerScanner) Scan() ([]ScannedDockerfile, error) {
+	var results []ScannedDockerfile
+
+	err := filepath.WalkDir(s.rootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.rootDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+
+		// 排除的目录整体跳过
+		if s.excluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !matchGlob(s.pattern, rel) {
+			return nil
+		}
+
+		results = append(results, s.newResult(rel))
+		return nil
+	})
+
+	return results, err
+}
+
+// excluded 检查相对路径是否被排除
+func (s *DockerScanner) excluded(rel string) bool {
+	for _, exclude := range s.exclude {
+		if matchGlob(exclude, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// newResult 为扫描到的 Dockerfile 生成构建信息
+func (s *DockerScanner) newResult(dockerfilePath string) ScannedDockerfile {
+	// 获取上下文目录（Dockerfile 所在目录）
+	contextDir := path.Dir(dockerfilePath)
+
+	// 根据目录名生成镜像名
+	dirName := path.Base(contextDir)
+	if contextDir == "." {
+		if abs, err := filepath.Abs(s.rootDir); err == nil {
+			dirName = filepath.Base(abs)
+		}
+	}
+	imageName := s.imagePrefix
+	if imageName != "" && !strings.HasSuffix(imageName, "/") {
+		imageName += "/"
+	}
+	imageName += dirName
+
+	result := ScannedDockerfile{
+		Name:       dirName,
+		Dockerfile: dockerfilePath,
+		Context:    contextDir,
+		ImageName:  imageName,
+		Tag:        s.tag,
+		Platforms:  s.platforms,
+	}
+
+	// 按 Dockerfile 路径或所在目录查找覆盖配置
+	key, override, ok := s.lookupOverride(dockerfilePath, contextDir)
+	if !ok {
+		return result
+	}
+	result.Override = key
+	if override.ImageName != "" {
+		result.ImageName = override.ImageName
+	}
+	if override.Tag != "" {
+		result.Tag = override.Tag
+	}
+	if len(override.Platforms) > 0 {
+		result.Platforms = override.Platforms
+	}
+	result.BuildArgs = maps.Clone(override.BuildArgs)
+	return result
+}
+
+// lookupOverride 查找覆盖配置，键可以是 Dockerfile 路径或其所在目录
+// Dockerfile 路径优先于所在目录；按键名顺序查找，保证多个键同时匹配时结果稳定
+func (s *DockerScanner) lookupOverride(dockerfilePath, contextDir string) (string, ScanOverride, bool) {
+	keys := slices.Sorted(maps.Keys(s.overrides))
+	for _, target := range []string{dockerfilePath, contextDir} {
+		for _, key := range keys {
+			if overrideKey(key) == target {
+				return key, s.overrides[key], true
+			}
+		}
+	}
+	return "", ScanOverride{}, false
+}
+
+// overrideKey 规范化 overrides 的键，与扫描结果中的相对路径比较
+func overrideKey(key string) string {
+	return path.Clean(filepath.ToSlash(key))
+}
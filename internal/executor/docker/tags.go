@@ -0,0 +1,154 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/gitinfo"
+)
+
+// 标签策略
+const (
+	StrategySemver                = "semver"                   // 1.4.2 → 1.4.2, 1.4, 1
+	StrategyGitSHA                = "git-sha"                  // 当前提交的短哈希
+	StrategyBranch                = "branch"                   // 当前分支名（转换为合法标签）
+	StrategyTimestamp             = "timestamp"                // UTC 时间，如 20260102-150405
+	StrategyLatest                = "latest"                   // 总是追加 latest
+	StrategyLatestOnDefaultBranch = "latest-on-default-branch" // 仅在默认分支上追加 latest
+)
+
+// tagStrategies 支持的标签策略
+var tagStrategies = []string{
+	StrategySemver, StrategyGitSHA, StrategyBranch, StrategyTimestamp, StrategyLatest, StrategyLatestOnDefaultBranch,
+}
+
+// semverPattern 语义化版本（允许 v 前缀）
+var semverPattern = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?$`)
+
+// invalidTagChars 标签中不允许出现的字符
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// tagResolver 按标签策略从主标签派生其他标签
+type tagResolver struct {
+	strategies []string
+	git        *gitinfo.Info // 未检测到 git 仓库时为 nil
+	now        time.Time
+	lenient    bool // 静默跳过非语义化版本的标签（推送任务的镜像常混有 latest、git-sha 等标签）
+}
+
+// newTagResolver 创建标签解析器，仅在策略依赖 git 时读取仓库信息
+func newTagResolver(strategies []string, gitDir string, handler executor.OutputHandler) *tagResolver {
+	r := &tagResolver{strategies: strategies, now: time.Now().UTC()}
+
+	needsGit := slices.ContainsFunc(strategies, func(s string) bool {
+		return s == StrategyGitSHA || s == StrategyBranch || s == StrategyLatestOnDefaultBranch
+	})
+	if !needsGit {
+		return r
+	}
+	if gitDir == "" {
+		gitDir = "."
+	}
+	info, err := gitinfo.Detect(gitDir)
+	if err != nil {
+		handler(fmt.Sprintf("⚠️  [WARN] 未检测到 git 仓库，跳过依赖 git 的标签策略: %v", err), false)
+		return r
+	}
+	r.git = info
+	return r
+}
+
+// derive 返回 tag 按策略派生出的标签（不含 tag 本身，已去重），以及跳过策略的说明
+func (r *tagResolver) derive(tag string) (tags, notes []string) {
+	add := func(t string) {
+		if t != "" && t != tag && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+
+	for _, strategy := range r.strategies {
+		switch strategy {
+		case StrategySemver:
+			m := semverPattern.FindStringSubmatch(tag)
+			switch {
+			case m == nil && r.lenient:
+			case m == nil:
+				notes = append(notes, fmt.Sprintf("⚠️  [WARN] 标签 %s 不是语义化版本，跳过 semver 策略", tag))
+			case m[5] != "":
+				// 预发布版本不应覆盖 1.4 / 1 这类稳定标签
+				notes = append(notes, fmt.Sprintf("ℹ️  [INFO] %s 是预发布版本，semver 策略不追加主/次版本标签", tag))
+			default:
+				add(m[1] + m[2] + "." + m[3])
+				add(m[1] + m[2])
+			}
+		case StrategyGitSHA:
+			if r.git != nil {
+				add(r.git.ShortCommit)
+			}
+		case StrategyBranch:
+			if r.git == nil {
+				continue
+			}
+			if r.git.Branch == "" {
+				notes = append(notes, "ℹ️  [INFO] 当前处于分离 HEAD，跳过 branch 策略")
+				continue
+			}
+			add(sanitizeTag(r.git.Branch))
+		case StrategyTimestamp:
+			add(r.now.Format("20060102-150405"))
+		case StrategyLatest:
+			add("latest")
+		case StrategyLatestOnDefaultBranch:
+			if r.git != nil && r.git.IsDefaultBranch() {
+				add("latest")
+			}
+		}
+	}
+	return tags, notes
+}
+
+// sanitizeTag 将任意字符串（如分支名 feature/login）转换为合法的镜像标签
+func sanitizeTag(s string) string {
+	s = invalidTagChars.ReplaceAllString(s, "-")
+	s = strings.TrimLeft(s, ".-")
+	if len(s) > 128 {
+		s = s[:128]
+	}
+	return s
+}
+
+// splitTag 拆分镜像名称与标签，未指定标签时返回 latest
+func splitTag(image string) (string, string) {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, "latest"
+}
+
+// normalizeStrategies 合并已废弃的 latest 开关与标签策略
+func normalizeStrategies(strategies []string, latest bool) []string {
+	out := slices.Clone(strategies)
+	if latest && !slices.Contains(out, StrategyLatest) {
+		out = append(out, StrategyLatest)
+	}
+	return out
+}
+
+// validateStrategies 检查标签策略名称
+func validateStrategies(strategies []string, field string, addError func(field, msg string)) {
+	seen := make(map[string]bool)
+	for i, s := range strategies {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		switch {
+		case !slices.Contains(tagStrategies, s):
+			addError(f, fmt.Sprintf("未知的标签策略: %s (可选: %s)", s, strings.Join(tagStrategies, ", ")))
+		case seen[s]:
+			addError(f, fmt.Sprintf("标签策略重复: %s", s))
+		}
+		seen[s] = true
+	}
+}
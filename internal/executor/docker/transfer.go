@@ -0,0 +1,363 @@
+package docker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
+	"golang.org/x/crypto/ssh"
+)
+
+// TransferTarget 镜像传输的目标服务器
+type TransferTarget struct {
+	Name   string
+	Server config.Server
+	Jumps  []config.Server // 依次经过的跳板机
+}
+
+// localImage 待传输的本地镜像
+type localImage struct {
+	name string // docker save 使用的镜像名称
+	id   string
+	size int64 // 镜像大小（估算传输量）
+}
+
+// ImageTransferExecutor 镜像传输执行器
+// 将 docker save 的输出（可选压缩）经 SSH 连接直接传给服务器上的 docker load，不经过 Registry 与本地临时文件
+type ImageTransferExecutor struct {
+	*executor.BaseExecutor
+	targets  []TransferTarget
+	images   []string
+	compress string
+	exports  map[string][]executor.ImageExport // 已导出到文件的镜像
+	pool     *sshclient.Pool                   // 为 nil 时单独建立连接
+	prompt   executor.PromptFunc
+	progress executor.ProgressFunc
+}
+
+// NewImageTransferExecutor 创建镜像传输执行器
+func NewImageTransferExecutor(taskName string, cfg TransferConfig, targets []TransferTarget) (*ImageTransferExecutor, error) {
+	e := &ImageTransferExecutor{
+		BaseExecutor: executor.NewBaseExecutor(taskName, executor.TypeImageTransfer),
+		targets:      targets,
+		images:       cfg.Images,
+		compress:     cfg.Compress,
+	}
+	if e.compress == "" {
+		e.compress = "none"
+	}
+
+	// 提前检查认证配置（如密钥文件不存在）
+	for _, target := range targets {
+		if err := sshclient.CheckRoute(target.Server, target.Jumps); err != nil {
+			return nil, fmt.Errorf("服务器 %s: %w", target.Name, err)
+		}
+	}
+
+	if cfg.Timeout > 0 {
+		e.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
+	} else {
+		e.SetTimeout(30 * time.Minute) // 传输默认 30 分钟
+	}
+
+	return e, nil
+}
+
+// SetImages 设置要传输的镜像列表
+func (e *ImageTransferExecutor) SetImages(images []string) {
+	e.images = images
+}
+
+// SetExports 设置已导出到文件的镜像
+func (e *ImageTransferExecutor) SetExports(exports map[string][]executor.ImageExport) {
+	e.exports = exports
+}
+
+// SetPrompt 设置交互式输入（如加密私钥的密码短语）
+func (e *ImageTransferExecutor) SetPrompt(prompt executor.PromptFunc) {
+	e.prompt = prompt
+}
+
+// SetProgress 设置进度上报
+func (e *ImageTransferExecutor) SetProgress(progress executor.ProgressFunc) {
+	e.progress = progress
+}
+
+// SetPool 设置 SSH 连接池（复用其他任务建立的连接）
+func (e *ImageTransferExecutor) SetPool(pool *sshclient.Pool) {
+	e.pool = pool
+}
+
+// Execute 传输镜像到各服务器
+func (e *ImageTransferExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
+	ctx, cancel := context.WithTimeout(ctx, e.GetTimeout())
+	defer cancel()
+
+	if len(e.images) == 0 {
+		handler("⏭️  没有需要传输的镜像", false)
+		return nil
+	}
+
+	images, err := e.prepare(ctx, handler)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, img := range images {
+		total += img.size
+	}
+	handler(fmt.Sprintf("📦 待传输 %d 个镜像（约 %s，压缩: %s）", len(images), executor.FormatBytes(total), e.compress), false)
+	for _, img := range images {
+		handler(fmt.Sprintf("   - %s (%s)", img.name, shortID(img.id)), false)
+	}
+	handler("", false)
+
+	for i, target := range e.targets {
+		if len(e.targets) > 1 {
+			handler(fmt.Sprintf("🖥️  [%d/%d] 传输到服务器: %s", i+1, len(e.targets), target.Name), false)
+		}
+		if err := e.transferTo(ctx, target, images, total, handler); err != nil {
+			return fmt.Errorf("传输镜像到 %s 失败: %w", target.Name, err)
+		}
+		handler("", false)
+	}
+
+	handler(fmt.Sprintf("✅ 镜像传输完成: %d 个镜像 → %d 台服务器", len(images), len(e.targets)), false)
+	return nil
+}
+
+// prepare 查询本地镜像 ID 与大小；单平台导出到文件的镜像先加载到本地
+func (e *ImageTransferExecutor) prepare(ctx context.Context, handler executor.OutputHandler) ([]localImage, error) {
+	var images []localImage
+	seen := make(map[string]bool)
+	for _, image := range e.images {
+		if seen[image] {
+			continue
+		}
+		seen[image] = true
+
+		source := image
+		if exports := e.exports[image]; len(exports) > 0 {
+			if multiPlatformExport(exports) {
+				return nil, fmt.Errorf("多平台镜像 %s 已导出到文件，docker load 只能加载单个平台（请改用 docker-push，或只构建目标服务器的平台）", image)
+			}
+			if err := loadExportFile(ctx, e.Name(), e.GetTimeout(), exports[0], handler); err != nil {
+				return nil, err
+			}
+			source = exports[0].Image
+		}
+
+		out, err := dockerOutput(ctx, "image", "inspect", "--format", "{{.Id}} {{.Size}}", source)
+		if err != nil {
+			return nil, fmt.Errorf("本地镜像不存在 [%s]: %w（多平台构建的镜像不会加载到本地）", source, err)
+		}
+		fields := strings.Fields(out)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("解析 docker image inspect 输出失败: %s", strings.TrimSpace(out))
+		}
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		images = append(images, localImage{name: source, id: fields[0], size: size})
+	}
+	return images, nil
+}
+
+// transferTo 连接服务器，传输镜像并校验镜像 ID
+func (e *ImageTransferExecutor) transferTo(ctx context.Context, target TransferTarget, images []localImage, total int64, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("🔗 连接服务器: %s@%s", target.Server.Username, sshclient.Address(target.Server)), false)
+	client, release, err := sshclient.Connect(ctx, e.pool, target.Name, target.Server, sshclient.Options{
+		Log:    func(msg string) { handler(msg, false) },
+		Prompt: sshclient.PromptFunc(e.prompt),
+		Jumps:  target.Jumps,
+	})
+	if err != nil {
+		return err
+	}
+	defer release()
+	handler("✅ SSH 连接成功", false)
+
+	handler(fmt.Sprintf("🚚 docker save | %s", remoteLoadCommand(e.compress)), false)
+	start := time.Now()
+	progress := executor.NewByteProgress(e.progress, total)
+	sent, err := e.stream(ctx, client, images, progress, handler)
+	if err != nil {
+		return err
+	}
+	progress.Finish()
+	handler(fmt.Sprintf("📊 镜像数据 %s，实际传输 %s，耗时 %s",
+		executor.FormatBytes(progress.Done()), executor.FormatBytes(sent), executor.FormatDuration(time.Since(start))), false)
+
+	return e.verify(ctx, client, images, handler)
+}
+
+// stream 运行 docker save（可选压缩）并将输出作为服务器上 docker load 的标准输入，返回实际传输的字节数
+func (e *ImageTransferExecutor) stream(ctx context.Context, client *ssh.Client, images []localImage, progress *executor.ByteProgress, handler executor.OutputHandler) (int64, error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	args := []string{"save"}
+	for _, img := range images {
+		args = append(args, img.name)
+	}
+	var saveErr bytes.Buffer
+	save := exec.CommandContext(ctx, "docker", args...)
+	save.Stderr = &saveErr
+	stdout, err := save.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := save.Start(); err != nil {
+		return 0, fmt.Errorf("启动 docker save 失败: %w", err)
+	}
+
+	// 进度按压缩前的镜像数据统计
+	data := progress.Reader(stdout)
+	var compressErr func() error
+	switch e.compress {
+	case "gzip":
+		src := data
+		pr, pw := io.Pipe()
+		done := make(chan error, 1)
+		go func() {
+			zw := gzip.NewWriter(pw)
+			_, err := io.Copy(zw, src)
+			if err == nil {
+				err = zw.Close()
+			}
+			pw.CloseWithError(err)
+			done <- err
+		}()
+		data = pr
+		compressErr = func() error {
+			pr.Close() // 远程提前退出时结束压缩
+			return <-done
+		}
+	case "zstd":
+		var zstdErr bytes.Buffer
+		zstd := exec.CommandContext(ctx, "zstd", "-q", "-c", "-T0")
+		zstd.Stdin = data
+		zstd.Stderr = &zstdErr
+		out, err := zstd.StdoutPipe()
+		if err != nil {
+			return 0, err
+		}
+		if err := zstd.Start(); err != nil {
+			cancel()
+			save.Wait()
+			return 0, fmt.Errorf("启动 zstd 失败（本地需要安装 zstd）: %w", err)
+		}
+		data = out
+		compressErr = func() error {
+			if err := zstd.Wait(); err != nil {
+				if msg := strings.TrimSpace(zstdErr.String()); msg != "" {
+					return fmt.Errorf("%s", msg)
+				}
+				return err
+			}
+			return nil
+		}
+	}
+
+	session, err := sshclient.NewSession(ctx, client)
+	if err != nil {
+		cancel()
+		save.Wait()
+		return 0, fmt.Errorf("创建 SSH session 失败: %w", err)
+	}
+	defer session.Close()
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	sent := &countingReader{r: data}
+	var out bytes.Buffer
+	session.Stdin = sent
+	session.Stdout = &out
+	session.Stderr = &out
+	loadErr := session.Run(remoteLoadCommand(e.compress))
+
+	// 远程失败时终止本地 docker save
+	if loadErr != nil {
+		cancel()
+	}
+	var zipErr error
+	if compressErr != nil {
+		zipErr = compressErr()
+	}
+	saveWaitErr := save.Wait()
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line != "" {
+			handler(line, loadErr != nil)
+		}
+	}
+
+	switch {
+	case parent.Err() != nil:
+		return 0, parent.Err()
+	case saveWaitErr != nil && strings.TrimSpace(saveErr.String()) != "":
+		return 0, fmt.Errorf("docker save 失败: %s", strings.TrimSpace(saveErr.String()))
+	case loadErr != nil:
+		return 0, fmt.Errorf("服务器上 docker load 失败: %w", loadErr)
+	case saveWaitErr != nil:
+		return 0, fmt.Errorf("docker save 失败: %w", saveWaitErr)
+	case zipErr != nil:
+		return 0, fmt.Errorf("压缩镜像数据失败: %w", zipErr)
+	}
+	return sent.n, nil
+}
+
+// verify 校验服务器上的镜像 ID 与本地一致
+func (e *ImageTransferExecutor) verify(ctx context.Context, client *ssh.Client, images []localImage, handler executor.OutputHandler) error {
+	for _, img := range images {
+		out, err := sshclient.Output(ctx, client, "docker image inspect --format '{{.Id}}' "+sshclient.Quote(img.name))
+		if err != nil {
+			return fmt.Errorf("服务器上未找到镜像 %s: %w", img.name, err)
+		}
+		if id := strings.TrimSpace(out); id != img.id {
+			return fmt.Errorf("镜像 ID 不一致 [%s]: 本地 %s，服务器 %s", img.name, shortID(img.id), shortID(id))
+		}
+		handler(fmt.Sprintf("✅ 镜像已加载: %s (%s)", img.name, shortID(img.id)), false)
+	}
+	return nil
+}
+
+// remoteLoadCommand 返回服务器上加载镜像的命令
+func remoteLoadCommand(compress string) string {
+	switch compress {
+	case "gzip":
+		return "gzip -dc | docker load"
+	case "zstd":
+		return "zstd -dc | docker load"
+	}
+	return "docker load"
+}
+
+// shortID 返回镜像 ID 的短格式，如 sha256:0123456789ab
+func shortID(id string) string {
+	algo, hex, ok := strings.Cut(id, ":")
+	if !ok || len(hex) <= 12 {
+		return id
+	}
+	return algo + ":" + hex[:12]
+}
+
+// countingReader 统计读取字节数的 Reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	return n, err
+}
@@ -2,6 +2,8 @@ package executor
 
 import (
 	"context"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -37,13 +39,15 @@ type Result struct {
 
 // ExecutorType 执行器类型常量
 const (
-	TypeMaven       = "maven"
-	TypeDockerBuild = "docker-build"
-	TypeDockerPush  = "docker-push"
-	TypeSSH         = "ssh"
-	TypeCommand     = "command"
-	TypeGoBuild     = "go-build"
-	TypeShell       = "shell"
+	TypeMaven         = "maven"
+	TypeDockerBuild   = "docker-build"
+	TypeDockerPush    = "docker-push"
+	TypeSSH           = "ssh"
+	TypeCommand       = "command"
+	TypeGoBuild       = "go-build"
+	TypeShell         = "shell"
+	TypeUpload        = "upload"
+	TypeImageTransfer = "image-transfer"
 )
 
 // BaseExecutor 基础执行器（可嵌入其他执行器）
@@ -108,3 +112,19 @@ func (e *BaseExecutor) GetTimeout() time.Duration {
 func (e *BaseExecutor) GetEnv() []string {
 	return e.env
 }
+
+// ExpandHomePath 展开 ~ 为 home 目录
+func ExpandHomePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
+
+// IsAbsPath 检查是否为绝对路径
+func IsAbsPath(path string) bool {
+	return len(path) > 0 && path[0] == '/'
+}
@@ -0,0 +1,83 @@
+package gobuild
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+func init() {
+	executor.Register(executor.Definition{
+		Type:      executor.TypeGoBuild,
+		NewConfig: func() config.TaskSettings { return &Config{} },
+		New: func(_ *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+			return NewGoBuildExecutor(taskName, *settings.(*Config)), nil
+		},
+	})
+}
+
+// Config Go 构建任务配置
+type Config struct {
+	config.CommonConfig `yaml:",inline"`
+
+	WorkingDir string `yaml:"working_dir,omitempty"`
+	Command    string `yaml:"command,omitempty"`     // 自定义命令
+	Script     string `yaml:"script,omitempty"`      // 自定义脚本
+	GoCommand  string `yaml:"go_command,omitempty"`  // go 子命令: build/test/generate (默认 build)
+	GoOS       string `yaml:"goos,omitempty"`        // 目标操作系统
+	GoArch     string `yaml:"goarch,omitempty"`      // 目标架构
+	Output     string `yaml:"output,omitempty"`      // 输出文件路径 (-o)
+	LDFlags    string `yaml:"ldflags,omitempty"`     // 链接标志 (-ldflags)
+	BuildTags  string `yaml:"tags,omitempty"`        // 构建标签 (-tags)
+	CGOEnabled *bool  `yaml:"cgo_enabled,omitempty"` // CGO 开关 (指针区分未设置和 false)
+	GoPrivate  string `yaml:"goprivate,omitempty"`   // GOPRIVATE 环境变量
+	GoProxy    string `yaml:"goproxy,omitempty"`     // GOPROXY 环境变量
+	Race       bool   `yaml:"race,omitempty"`        // 竞态检测 (-race)
+	Trimpath   bool   `yaml:"trimpath,omitempty"`    // 移除路径 (-trimpath)
+	Mod        string `yaml:"mod,omitempty"`         // 模块模式 (-mod=vendor/readonly/mod)
+	Packages   string `yaml:"packages,omitempty"`    // 目标包 (默认 .)
+	GoVerbose  bool   `yaml:"go_verbose,omitempty"`  // 详细输出 (-v)
+}
+
+// Validate 验证 Go 构建任务（可接受默认参数，仅校验显式指定的值）
+func (c *Config) Validate(v *config.TaskValidation) {
+	if c.GoCommand != "" && !slices.Contains(goCommands, c.GoCommand) {
+		v.AddError("go_command",
+			fmt.Sprintf("无效的 Go 子命令: %s (支持: %s)", c.GoCommand, strings.Join(goCommands, ", ")))
+	}
+
+	if c.Mod != "" && !slices.Contains(goModModes, c.Mod) {
+		v.AddError("mod",
+			fmt.Sprintf("无效的模块模式: %s (支持: %s)", c.Mod, strings.Join(goModModes, ", ")))
+	}
+
+	switch {
+	case c.GoOS != "" && !isValidGOOS(c.GoOS):
+		v.AddError("goos", fmt.Sprintf("不支持的 GOOS: %s", c.GoOS))
+	case c.GoArch != "" && !isValidGOARCH(c.GoArch):
+		v.AddError("goarch", fmt.Sprintf("不支持的 GOARCH: %s", c.GoArch))
+	case c.GoOS != "" && c.GoArch != "" && !slices.Contains(goPlatforms[c.GoOS], c.GoArch):
+		v.AddError("goarch",
+			fmt.Sprintf("%s 不支持 GOARCH %s (支持: %s)", c.GoOS, c.GoArch, strings.Join(goPlatforms[c.GoOS], ", ")))
+	}
+}
+
+// Expand 展开变量引用
+func (c *Config) Expand(expand func(string) string) {
+	c.WorkingDir = expand(c.WorkingDir)
+	c.Command = expand(c.Command)
+	c.Script = expand(c.Script)
+	c.GoCommand = expand(c.GoCommand)
+	c.GoOS = expand(c.GoOS)
+	c.GoArch = expand(c.GoArch)
+	c.Output = expand(c.Output)
+	c.LDFlags = expand(c.LDFlags)
+	c.BuildTags = expand(c.BuildTags)
+	c.GoPrivate = expand(c.GoPrivate)
+	c.GoProxy = expand(c.GoProxy)
+	c.Mod = expand(c.Mod)
+	c.Packages = expand(c.Packages)
+}
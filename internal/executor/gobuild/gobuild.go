@@ -1,4 +1,4 @@
-package executor
+package gobuild
 
 import (
 	"context"
@@ -7,12 +7,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
 )
 
 // GoBuildExecutor Go 构建执行器
 type GoBuildExecutor struct {
-	*BaseExecutor
+	*executor.BaseExecutor
 	goCommand  string // build/test/generate
 	command    string // 自定义命令
 	script     string // 自定义脚本
@@ -32,9 +32,9 @@ type GoBuildExecutor struct {
 }
 
 // NewGoBuildExecutor 创建 Go 构建执行器
-func NewGoBuildExecutor(taskName string, cfg config.TaskConfig) *GoBuildExecutor {
+func NewGoBuildExecutor(taskName string, cfg Config) *GoBuildExecutor {
 	e := &GoBuildExecutor{
-		BaseExecutor: NewBaseExecutor(taskName, TypeGoBuild),
+		BaseExecutor: executor.NewBaseExecutor(taskName, executor.TypeGoBuild),
 		goCommand:    cfg.GoCommand,
 		command:      cfg.Command,
 		script:       cfg.Script,
@@ -95,7 +95,7 @@ func (e *GoBuildExecutor) setupGoEnv() {
 }
 
 // Execute 执行 Go 构建
-func (e *GoBuildExecutor) Execute(ctx context.Context, handler OutputHandler) error {
+func (e *GoBuildExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
 	// 优先使用脚本模式
 	if e.script != "" {
 		return e.executeScript(ctx, handler)
@@ -111,13 +111,13 @@ func (e *GoBuildExecutor) Execute(ctx context.Context, handler OutputHandler) er
 }
 
 // executeCommand 执行自定义命令
-func (e *GoBuildExecutor) executeCommand(ctx context.Context, handler OutputHandler) error {
+func (e *GoBuildExecutor) executeCommand(ctx context.Context, handler executor.OutputHandler) error {
 	handler(fmt.Sprintf("🔨 执行 Go 命令: %s", e.command), false)
 	handler(fmt.Sprintf("📁 工作目录: %s", e.getWorkDir()), false)
 	e.printEnvInfo(handler)
 	handler("", false)
 
-	runner := NewCommandRunner(e.Name(), e.command)
+	runner := executor.NewCommandRunner(e.Name(), e.command)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
 	runner.SetEnv(e.GetEnv())
@@ -126,10 +126,10 @@ func (e *GoBuildExecutor) executeCommand(ctx context.Context, handler OutputHand
 }
 
 // executeScript 执行构建脚本
-func (e *GoBuildExecutor) executeScript(ctx context.Context, handler OutputHandler) error {
+func (e *GoBuildExecutor) executeScript(ctx context.Context, handler executor.OutputHandler) error {
 	scriptPath := e.script
-	if e.workingDir != "" && !isAbsPath(scriptPath) {
-		scriptPath = e.workingDir + "/" + scriptPath
+	if e.GetWorkingDir() != "" && !executor.IsAbsPath(scriptPath) {
+		scriptPath = e.GetWorkingDir() + "/" + scriptPath
 	}
 
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
@@ -141,7 +141,7 @@ func (e *GoBuildExecutor) executeScript(ctx context.Context, handler OutputHandl
 	e.printEnvInfo(handler)
 	handler("", false)
 
-	runner := NewScriptRunner(e.Name(), scriptPath)
+	runner := executor.NewScriptRunner(e.Name(), scriptPath)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
 	runner.SetEnv(e.GetEnv())
@@ -150,7 +150,7 @@ func (e *GoBuildExecutor) executeScript(ctx context.Context, handler OutputHandl
 }
 
 // executeDefaultBuild 执行默认构建
-func (e *GoBuildExecutor) executeDefaultBuild(ctx context.Context, handler OutputHandler) error {
+func (e *GoBuildExecutor) executeDefaultBuild(ctx context.Context, handler executor.OutputHandler) error {
 	args := e.buildDefaultArgs()
 	logLine := strings.Join(args, " ")
 
@@ -159,7 +159,7 @@ func (e *GoBuildExecutor) executeDefaultBuild(ctx context.Context, handler Outpu
 	e.printEnvInfo(handler)
 	handler("", false)
 
-	runner := NewCommandRunnerWithArgs(e.Name(), args[0], args[1:])
+	runner := executor.NewCommandRunnerWithArgs(e.Name(), args[0], args[1:])
 	runner.SetShell(false)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
@@ -268,7 +268,7 @@ func (e *GoBuildExecutor) buildGenerateArgs() []string {
 }
 
 // printEnvInfo 打印环境变量信息
-func (e *GoBuildExecutor) printEnvInfo(handler OutputHandler) {
+func (e *GoBuildExecutor) printEnvInfo(handler executor.OutputHandler) {
 	if e.goos != "" || e.goarch != "" {
 		handler(fmt.Sprintf("🎯 目标平台: %s/%s", e.getGOOS(), e.getGOARCH()), false)
 	}
@@ -315,8 +315,8 @@ func (e *GoBuildExecutor) getGOARCH() string {
 
 // getWorkDir 获取工作目录
 func (e *GoBuildExecutor) getWorkDir() string {
-	if e.workingDir != "" {
-		return e.workingDir
+	if e.GetWorkingDir() != "" {
+		return e.GetWorkingDir()
 	}
 	dir, _ := os.Getwd()
 	return dir
@@ -0,0 +1,47 @@
+package gobuild
+
+import "slices"
+
+// goPlatforms Go 支持的 GOOS/GOARCH 组合（来自 `go tool dist list`）
+var goPlatforms = map[string][]string{
+	"aix":       {"ppc64"},
+	"android":   {"386", "amd64", "arm", "arm64"},
+	"darwin":    {"amd64", "arm64"},
+	"dragonfly": {"amd64"},
+	"freebsd":   {"386", "amd64", "arm", "arm64"},
+	"illumos":   {"amd64"},
+	"ios":       {"amd64", "arm64"},
+	"js":        {"wasm"},
+	"linux": {
+		"386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le",
+		"mipsle", "ppc64", "ppc64le", "riscv64", "s390x",
+	},
+	"netbsd":  {"386", "amd64", "arm", "arm64"},
+	"openbsd": {"386", "amd64", "arm", "arm64", "ppc64", "riscv64"},
+	"plan9":   {"386", "amd64", "arm"},
+	"solaris": {"amd64"},
+	"wasip1":  {"wasm"},
+	"windows": {"386", "amd64", "arm64"},
+}
+
+// goModModes go build -mod 支持的取值
+var goModModes = []string{"mod", "readonly", "vendor"}
+
+// goCommands go-build 任务支持的子命令
+var goCommands = []string{"build", "test", "generate"}
+
+// isValidGOOS 检查 GOOS 是否受支持
+func isValidGOOS(goos string) bool {
+	_, ok := goPlatforms[goos]
+	return ok
+}
+
+// isValidGOARCH 检查 GOARCH 是否被任一 GOOS 支持
+func isValidGOARCH(goarch string) bool {
+	for _, arches := range goPlatforms {
+		if slices.Contains(arches, goarch) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,4 +1,4 @@
-package executor
+package maven
 
 import (
 	"context"
@@ -6,20 +6,20 @@ import (
 	"os"
 	"time"
 
-	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
 )
 
 // MavenExecutor Maven 构建执行器
 type MavenExecutor struct {
-	*BaseExecutor
+	*executor.BaseExecutor
 	command string
 	script  string
 }
 
 // NewMavenExecutor 创建 Maven 执行器
-func NewMavenExecutor(taskName string, cfg config.TaskConfig) *MavenExecutor {
+func NewMavenExecutor(taskName string, cfg Config) *MavenExecutor {
 	e := &MavenExecutor{
-		BaseExecutor: NewBaseExecutor(taskName, TypeMaven),
+		BaseExecutor: executor.NewBaseExecutor(taskName, executor.TypeMaven),
 		command:      cfg.Command,
 		script:       cfg.Script,
 	}
@@ -40,7 +40,7 @@ func NewMavenExecutor(taskName string, cfg config.TaskConfig) *MavenExecutor {
 }
 
 // Execute 执行 Maven 构建
-func (e *MavenExecutor) Execute(ctx context.Context, handler OutputHandler) error {
+func (e *MavenExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
 	// 优先使用脚本
 	if e.script != "" {
 		return e.executeScript(ctx, handler)
@@ -56,7 +56,7 @@ func (e *MavenExecutor) Execute(ctx context.Context, handler OutputHandler) erro
 }
 
 // executeCommand 执行 Maven 命令
-func (e *MavenExecutor) executeCommand(ctx context.Context, handler OutputHandler) error {
+func (e *MavenExecutor) executeCommand(ctx context.Context, handler executor.OutputHandler) error {
 	command := e.command
 	if command == "" {
 		command = "mvn clean package -DskipTests"
@@ -66,7 +66,7 @@ func (e *MavenExecutor) executeCommand(ctx context.Context, handler OutputHandle
 	handler(fmt.Sprintf("📁 工作目录: %s", e.getWorkDir()), false)
 	handler("", false)
 
-	runner := NewCommandRunner(e.Name(), command)
+	runner := executor.NewCommandRunner(e.Name(), command)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
 	runner.SetEnv(e.GetEnv())
@@ -75,11 +75,11 @@ func (e *MavenExecutor) executeCommand(ctx context.Context, handler OutputHandle
 }
 
 // executeScript 执行构建脚本
-func (e *MavenExecutor) executeScript(ctx context.Context, handler OutputHandler) error {
+func (e *MavenExecutor) executeScript(ctx context.Context, handler executor.OutputHandler) error {
 	// 检查脚本是否存在
 	scriptPath := e.script
-	if e.workingDir != "" && !isAbsPath(scriptPath) {
-		scriptPath = e.workingDir + "/" + scriptPath
+	if e.GetWorkingDir() != "" && !executor.IsAbsPath(scriptPath) {
+		scriptPath = e.GetWorkingDir() + "/" + scriptPath
 	}
 
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
@@ -90,7 +90,7 @@ func (e *MavenExecutor) executeScript(ctx context.Context, handler OutputHandler
 	handler(fmt.Sprintf("📁 工作目录: %s", e.getWorkDir()), false)
 	handler("", false)
 
-	runner := NewScriptRunner(e.Name(), scriptPath)
+	runner := executor.NewScriptRunner(e.Name(), scriptPath)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
 	runner.SetEnv(e.GetEnv())
@@ -100,14 +100,9 @@ func (e *MavenExecutor) executeScript(ctx context.Context, handler OutputHandler
 
 // getWorkDir 获取工作目录
 func (e *MavenExecutor) getWorkDir() string {
-	if e.workingDir != "" {
-		return e.workingDir
+	if e.GetWorkingDir() != "" {
+		return e.GetWorkingDir()
 	}
 	dir, _ := os.Getwd()
 	return dir
 }
-
-// isAbsPath 检查是否为绝对路径
-func isAbsPath(path string) bool {
-	return len(path) > 0 && path[0] == '/'
-}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// progressInterval 字节进度的最短上报间隔，避免传输大文件时刷屏
+const progressInterval = 200 * time.Millisecond
+
+// ByteProgress 按字节统计传输进度并通过 ProgressFunc 上报（限制上报频率）
+type ByteProgress struct {
+	mu       sync.Mutex
+	report   ProgressFunc
+	total    int64 // 预计总字节数，可能是估算值
+	done     int64
+	last     time.Time
+	finished bool
+}
+
+// NewByteProgress 创建字节进度，total 为预计传输的总字节数（未知时为 0），report 可以为 nil
+func NewByteProgress(report ProgressFunc, total int64) *ByteProgress {
+	return &ByteProgress{report: report, total: total}
+}
+
+// Add 累加已传输的字节数，首次达到预计总量时立即上报
+func (p *ByteProgress) Add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += n
+	reached := p.total > 0 && p.done >= p.total && !p.finished
+	if reached {
+		p.finished = true
+	} else if time.Since(p.last) < progressInterval {
+		return
+	}
+	p.send()
+}
+
+// Finish 上报最终进度（总量为估算值时，以实际传输的字节数作为总量）
+func (p *ByteProgress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = p.done
+	p.send()
+}
+
+// send 上报当前进度，已传输超过预计总量时按已传输计算
+func (p *ByteProgress) send() {
+	if p.report == nil {
+		return
+	}
+	p.last = time.Now()
+	total := max(p.total, p.done)
+	if total > 0 {
+		p.report(int(p.done), int(total), fmt.Sprintf("已传输 %s / %s", FormatBytes(p.done), FormatBytes(total)))
+	} else {
+		p.report(0, 0, "已传输 0 B")
+	}
+}
+
+// Done 返回已传输的字节数
+func (p *ByteProgress) Done() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
+
+// Reader 包装 r，读取时累加进度
+func (p *ByteProgress) Reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, progress: p}
+}
+
+// progressReader 统计读取字节数的 Reader
+type progressReader struct {
+	r        io.Reader
+	progress *ByteProgress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.progress.Add(int64(n))
+	}
+	return n, err
+}
+
+// FormatBytes 格式化字节数，如 1.5 MB
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTP"[exp])
+}
@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
+)
+
+// Definition 任务类型定义
+// 每个执行器包在 init 中注册自己的任务类型：配置结构（含校验与变量展开）和构造函数
+type Definition struct {
+	// Type 任务类型名称，对应配置中的 type 字段
+	Type string
+
+	// NewConfig 创建空的配置结构，任务 config 会被解码到该结构中
+	NewConfig func() config.TaskSettings
+
+	// New 根据解码后的配置创建执行器
+	New func(rt *Runtime, taskName string, settings config.TaskSettings) (Executor, error)
+
+	// Expand 可选，在流水线创建时将一个任务展开为多个子任务（如 docker-build 的 auto_scan）
+	// 返回 nil 表示不展开
+	Expand func(taskName string, settings config.TaskSettings) ([]SubTask, error)
+}
+
+// SubTask 展开后的子任务
+type SubTask struct {
+	Name     string
+	Settings config.TaskSettings
+}
+
+// Runtime 流水线运行时上下文，供执行器构造时读取全局配置和共享状态
+type Runtime struct {
+	Config       *config.Config           // 完整配置（registries、servers 等）
+	BuiltImages  []string                 // 此前任务已构建的镜像
+	PushedImages map[string]bool          // 已在构建阶段推送的镜像
+	Exports      map[string][]ImageExport // 已导出到文件的镜像（镜像名称 → 各平台导出）
+	Session      *Session                 // 本次运行内共享的状态（所有执行器共用同一个实例）
+	Prompt       PromptFunc               // 交互式输入（TUI 中弹出输入框），无法交互时为 nil
+	Progress     ProgressFunc             // 上报任务进度（任务卡片中显示百分比），可能为 nil
+	SSHPool      *sshclient.Pool          // 流水线共享的 SSH 连接池，为 nil 时每个任务单独连接
+	HostStatus   HostStatusFunc           // 上报多服务器任务中各服务器的状态（任务队列中显示），可能为 nil
+}
+
+// PromptFunc 向用户询问输入（如加密私钥的密码短语），secret 为 true 时输入不回显
+type PromptFunc func(ctx context.Context, question string, secret bool) (string, error)
+
+// ProgressFunc 上报任务进度，current/total 的单位由执行器决定（如已传输的字节数）
+type ProgressFunc func(current, total int, message string)
+
+// HostState 多服务器任务中单台服务器的状态
+type HostState int
+
+const (
+	HostPending HostState = iota // 等待执行
+	HostRunning                  // 执行中
+	HostSuccess                  // 执行成功
+	HostFailed                   // 执行失败
+	HostSkipped                  // 失败数超过上限后未执行
+)
+
+// HostStatusFunc 上报多服务器任务中单台服务器的状态
+type HostStatusFunc func(host string, state HostState)
+
+// ImageBuilder 构建镜像的执行器，流水线据此记录已构建的镜像
+type ImageBuilder interface {
+	// ImageNames 返回构建产生的所有镜像名称（主标签在前）
+	ImageNames() []string
+
+	// IsPushed 返回镜像是否已在构建阶段推送
+	IsPushed() bool
+}
+
+// ImageDetails 本地镜像详情（docker image inspect）
+type ImageDetails struct {
+	ID        string
+	Labels    map[string]string
+	Platforms []string // 如 linux/amd64
+}
+
+// ImageInspector 可查询构建结果的镜像构建器，流水线在构建成功后调用，结果记录到运行摘要
+type ImageInspector interface {
+	InspectImage(ctx context.Context) (*ImageDetails, error)
+}
+
+// ImageExport 导出到本地文件的镜像（每个平台一个文件）
+type ImageExport struct {
+	Image    string // 文件中的镜像名称（多平台时带平台后缀，如 app:1.0-linux-arm64）
+	Platform string // 平台，单平台构建未指定时为空
+	Path     string // 导出文件的绝对路径
+	Format   string // oci 或 docker
+}
+
+// ImageExporter 将镜像导出到文件的执行器，后续推送任务可从导出文件推送
+type ImageExporter interface {
+	ExportedImages() []ImageExport
+}
+
+// PushedImage 已推送的镜像
+type PushedImage struct {
+	Image    string // 推送的镜像名称（含标签）
+	Digest   string // 镜像摘要，如 sha256:...
+	Registry string // 目标 Registry 地址
+}
+
+// ImagePusher 推送镜像的执行器，流水线据此记录镜像摘要并提供 ${images.<name>.digest} 变量
+type ImagePusher interface {
+	PushedImages() []PushedImage
+}
+
+var (
+	registryMu  sync.RWMutex
+	definitions = make(map[string]Definition)
+)
+
+// Register 注册任务类型，同时向 config 注册其配置结构
+func Register(def Definition) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := definitions[def.Type]; exists {
+		panic(fmt.Sprintf("执行器重复注册: %s", def.Type))
+	}
+	definitions[def.Type] = def
+	config.RegisterTaskType(def.Type, def.NewConfig)
+}
+
+// Lookup 查找任务类型定义
+func Lookup(taskType string) (Definition, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	def, ok := definitions[taskType]
+	return def, ok
+}
+
+// Create 根据任务类型和配置创建执行器
+func Create(rt *Runtime, taskName, taskType string, settings config.TaskSettings) (Executor, error) {
+	def, ok := Lookup(taskType)
+	if !ok {
+		return nil, fmt.Errorf("不支持的任务类型: %s", taskType)
+	}
+	return def.New(rt, taskName, settings)
+}
+
+// ExpandTask 按任务类型展开任务，不支持展开的任务类型返回任务本身
+func ExpandTask(taskName, taskType string, settings config.TaskSettings) ([]SubTask, error) {
+	def, ok := Lookup(taskType)
+	if !ok {
+		return nil, fmt.Errorf("不支持的任务类型: %s", taskType)
+	}
+
+	if def.Expand != nil {
+		subTasks, err := def.Expand(taskName, settings)
+		if err != nil {
+			return nil, err
+		}
+		if subTasks != nil {
+			return subTasks, nil
+		}
+	}
+	return []SubTask{{Name: taskName, Settings: settings}}, nil
+}
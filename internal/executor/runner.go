@@ -17,7 +17,8 @@ type CommandRunner struct {
 	*BaseExecutor
 	command string
 	args    []string
-	shell   bool // 是否使用 shell 执行
+	shell   bool      // 是否使用 shell 执行
+	stdin   io.Reader // 标准输入（可选，用于传递密码等敏感数据）
 }
 
 // NewCommandRunner 创建命令运行器
@@ -44,6 +45,11 @@ func (r *CommandRunner) SetShell(shell bool) {
 	r.shell = shell
 }
 
+// SetStdin 设置标准输入
+func (r *CommandRunner) SetStdin(stdin io.Reader) {
+	r.stdin = stdin
+}
+
 // Execute 执行命令并实时流式输出
 func (r *CommandRunner) Execute(ctx context.Context, handler OutputHandler) error {
 	// 创建带超时的上下文
@@ -66,6 +72,9 @@ func (r *CommandRunner) Execute(ctx context.Context, handler OutputHandler) erro
 
 	// 设置环境变量
 	cmd.Env = append(os.Environ(), r.env...)
+	if r.stdin != nil {
+		cmd.Stdin = r.stdin
+	}
 
 	// 获取 stdout 和 stderr 管道
 	stdout, err := cmd.StdoutPipe()
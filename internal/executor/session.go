@@ -0,0 +1,86 @@
+package executor
+
+import "sync"
+
+// Session 一次流水线运行内共享的状态，由流水线创建并传给所有执行器
+// 目前用于记录已完成的一次性操作（如 Registry 登录），避免重复执行，并统计仍在使用登录状态的任务数
+type Session struct {
+	mu      sync.Mutex // 只保护 entries
+	entries map[string]*onceEntry
+}
+
+// onceEntry 一个 key 的执行状态，mu 串行化同一个 key 的执行
+type onceEntry struct {
+	mu   sync.Mutex
+	done bool
+	refs int // Acquire 后尚未 Release 的次数
+}
+
+// NewSession 创建运行会话
+func NewSession() *Session {
+	return &Session{entries: make(map[string]*onceEntry)}
+}
+
+// Once 对同一个 key 只成功执行一次 fn，返回本次是否执行了 fn
+// fn 失败时不记录，后续调用会重试；同一个 key 的并发调用等待前一次完成，不同 key 互不阻塞
+func (s *Session) Once(key string, fn func() error) (bool, error) {
+	entry := s.entry(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	return entry.run(fn)
+}
+
+// Acquire 与 Once 相同，成功后增加 key 的引用计数，使用完毕后须调用 Release
+func (s *Session) Acquire(key string, fn func() error) (bool, error) {
+	entry := s.entry(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	ran, err := entry.run(fn)
+	if err == nil {
+		entry.refs++
+	}
+	return ran, err
+}
+
+// Release 减少 key 的引用计数；最后一个引用释放且 cleanup 不为 nil 时执行 cleanup（如登出）并清除执行记录，
+// 之后的 Acquire 会重新执行。cleanup 执行期间同一个 key 的 Acquire 会等待
+func (s *Session) Release(key string, cleanup func()) {
+	entry := s.entry(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.refs > 0 {
+		entry.refs--
+	}
+	if entry.refs > 0 || cleanup == nil {
+		return
+	}
+	cleanup()
+	entry.done = false
+}
+
+// run 未执行成功过时执行 fn，调用方须持有 e.mu
+func (e *onceEntry) run(fn func() error) (bool, error) {
+	if e.done {
+		return false, nil
+	}
+	if err := fn(); err != nil {
+		return true, err
+	}
+	e.done = true
+	return true, nil
+}
+
+// entry 返回 key 的执行状态，不存在时创建
+func (s *Session) entry(key string) *onceEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &onceEntry{}
+		s.entries[key] = entry
+	}
+	return entry
+}
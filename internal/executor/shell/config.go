@@ -0,0 +1,39 @@
+package shell
+
+import (
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+func init() {
+	executor.Register(executor.Definition{
+		Type:      executor.TypeShell,
+		NewConfig: func() config.TaskSettings { return &Config{} },
+		New: func(_ *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+			return NewShellExecutor(taskName, *settings.(*Config)), nil
+		},
+	})
+}
+
+// Config Shell 任务配置
+type Config struct {
+	config.CommonConfig `yaml:",inline"`
+
+	WorkingDir string `yaml:"working_dir,omitempty"`
+	Command    string `yaml:"command,omitempty"`
+	Script     string `yaml:"script,omitempty"`
+}
+
+// Validate 验证 Shell 任务
+func (c *Config) Validate(v *config.TaskValidation) {
+	if c.Command == "" && c.Script == "" {
+		v.AddError("", "Shell 任务必须指定 command 或 script")
+	}
+}
+
+// Expand 展开变量引用
+func (c *Config) Expand(expand func(string) string) {
+	c.WorkingDir = expand(c.WorkingDir)
+	c.Command = expand(c.Command)
+	c.Script = expand(c.Script)
+}
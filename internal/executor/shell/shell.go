@@ -1,4 +1,4 @@
-package executor
+package shell
 
 import (
 	"context"
@@ -6,20 +6,20 @@ import (
 	"os"
 	"time"
 
-	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
 )
 
 // ShellExecutor Shell 命令执行器
 type ShellExecutor struct {
-	*BaseExecutor
+	*executor.BaseExecutor
 	command string
 	script  string
 }
 
 // NewShellExecutor 创建 Shell 执行器
-func NewShellExecutor(taskName string, cfg config.TaskConfig) *ShellExecutor {
+func NewShellExecutor(taskName string, cfg Config) *ShellExecutor {
 	e := &ShellExecutor{
-		BaseExecutor: NewBaseExecutor(taskName, TypeShell),
+		BaseExecutor: executor.NewBaseExecutor(taskName, executor.TypeShell),
 		command:      cfg.Command,
 		script:       cfg.Script,
 	}
@@ -40,7 +40,7 @@ func NewShellExecutor(taskName string, cfg config.TaskConfig) *ShellExecutor {
 }
 
 // Execute 执行 Shell 命令
-func (e *ShellExecutor) Execute(ctx context.Context, handler OutputHandler) error {
+func (e *ShellExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
 	// 优先使用脚本模式
 	if e.script != "" {
 		return e.executeScript(ctx, handler)
@@ -55,12 +55,12 @@ func (e *ShellExecutor) Execute(ctx context.Context, handler OutputHandler) erro
 }
 
 // executeCommand 执行 Shell 命令
-func (e *ShellExecutor) executeCommand(ctx context.Context, handler OutputHandler) error {
+func (e *ShellExecutor) executeCommand(ctx context.Context, handler executor.OutputHandler) error {
 	handler("🐚 执行 Shell 命令", false)
 	handler(fmt.Sprintf("📁 工作目录: %s", e.getWorkDir()), false)
 	handler("", false)
 
-	runner := NewCommandRunner(e.Name(), e.command)
+	runner := executor.NewCommandRunner(e.Name(), e.command)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
 	runner.SetEnv(e.GetEnv())
@@ -69,10 +69,10 @@ func (e *ShellExecutor) executeCommand(ctx context.Context, handler OutputHandle
 }
 
 // executeScript 执行脚本文件
-func (e *ShellExecutor) executeScript(ctx context.Context, handler OutputHandler) error {
+func (e *ShellExecutor) executeScript(ctx context.Context, handler executor.OutputHandler) error {
 	scriptPath := e.script
-	if e.workingDir != "" && !isAbsPath(scriptPath) {
-		scriptPath = e.workingDir + "/" + scriptPath
+	if e.GetWorkingDir() != "" && !executor.IsAbsPath(scriptPath) {
+		scriptPath = e.GetWorkingDir() + "/" + scriptPath
 	}
 
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
@@ -83,7 +83,7 @@ func (e *ShellExecutor) executeScript(ctx context.Context, handler OutputHandler
 	handler(fmt.Sprintf("📁 工作目录: %s", e.getWorkDir()), false)
 	handler("", false)
 
-	runner := NewScriptRunner(e.Name(), scriptPath)
+	runner := executor.NewScriptRunner(e.Name(), scriptPath)
 	runner.SetWorkingDir(e.getWorkDir())
 	runner.SetTimeout(e.GetTimeout())
 	runner.SetEnv(e.GetEnv())
@@ -93,8 +93,8 @@ func (e *ShellExecutor) executeScript(ctx context.Context, handler OutputHandler
 
 // getWorkDir 获取工作目录
 func (e *ShellExecutor) getWorkDir() string {
-	if e.workingDir != "" {
-		return e.workingDir
+	if e.GetWorkingDir() != "" {
+		return e.GetWorkingDir()
 	}
 	dir, _ := os.Getwd()
 	return dir
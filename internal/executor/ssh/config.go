@@ -0,0 +1,226 @@
+package ssh
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	executor.Register(executor.Definition{
+		Type:      executor.TypeSSH,
+		NewConfig: func() config.TaskSettings { return &Config{} },
+		New: func(rt *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+			cfg := *settings.(*Config)
+			// 通过 --server 限制目标服务器时只在其中的服务器上执行
+			var hosts []Host
+			for _, name := range rt.Config.TaskServers(&cfg) {
+				server, ok := rt.Config.Servers[name]
+				if !ok {
+					return nil, fmt.Errorf("服务器不存在: %s", name)
+				}
+				jumps, err := rt.Config.Route(name)
+				if err != nil {
+					return nil, err
+				}
+				hosts = append(hosts, Host{Name: name, Server: server, Jumps: jumps, Vars: rt.Config.ServerVariables(name)})
+			}
+			exec, err := NewSSHExecutor(taskName, cfg, hosts, rt.Prompt)
+			if err != nil {
+				return nil, err
+			}
+			exec.SetPool(rt.SSHPool)
+			exec.SetHostStatus(rt.HostStatus)
+			return exec, nil
+		},
+	})
+}
+
+// Config SSH 远程任务配置
+type Config struct {
+	config.CommonConfig  `yaml:",inline"`
+	config.RefreshConfig `yaml:",inline"` // 远程输出强制降级刷新（避免刷屏）
+
+	Server      string    `yaml:"server,omitempty"`
+	Servers     []string  `yaml:"servers,omitempty"`      // 多台服务器
+	ServerGroup string    `yaml:"server_group,omitempty"` // 服务器组（server_groups 中的名称，或服务器 groups 中声明的组）
+	ServerTag   string    `yaml:"server_tag,omitempty"`   // 带有该标签的所有服务器
+	Commands    []Command `yaml:"commands,omitempty"`     // 在同一个远程 shell 中依次执行
+	Script      string    `yaml:"script,omitempty"`
+	LocalScript string    `yaml:"local_script,omitempty"`
+
+	// 多服务器执行策略
+	Strategy    string `yaml:"strategy,omitempty"`     // serial(默认) | parallel | rolling
+	BatchSize   int    `yaml:"batch_size,omitempty"`   // rolling 每批的服务器数（默认 1）
+	MaxFailures int    `yaml:"max_failures,omitempty"` // 允许失败的服务器数，超过后不再执行后续批次（默认 0）
+	Pause       int    `yaml:"pause,omitempty"`        // 批次之间暂停的秒数
+}
+
+// Command commands 中的一条命令，可以直接写命令字符串，也可以写成带选项的映射
+//
+//	commands:
+//	  - "cd /opt/app"
+//	  - run: "docker compose pull"
+//	    ignore_error: true
+//	    timeout: 120
+type Command struct {
+	Run         string `yaml:"run"`
+	IgnoreError bool   `yaml:"ignore_error,omitempty"` // 失败（含超时）时继续执行后续命令
+	Timeout     int    `yaml:"timeout,omitempty"`      // 单条命令的超时时间（秒），未设置时只受任务 timeout 限制
+}
+
+// UnmarshalYAML 支持字符串形式的命令
+func (c *Command) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Run = node.Value
+		return nil
+	}
+	type plain Command
+	return node.Decode((*plain)(c))
+}
+
+// 多服务器执行策略
+const (
+	StrategySerial   = "serial"   // 逐台执行
+	StrategyParallel = "parallel" // 所有服务器同时执行
+	StrategyRolling  = "rolling"  // 每批 batch_size 台，批次之间可暂停
+)
+
+// Strategies 支持的多服务器执行策略
+var Strategies = []string{StrategySerial, StrategyParallel, StrategyRolling}
+
+// strategy 返回执行策略
+func (c *Config) strategy() string {
+	if c.Strategy == "" {
+		return StrategySerial
+	}
+	return c.Strategy
+}
+
+// TargetServers 返回任务连接的服务器（不含 server_group 的成员与 server_tag 选择的服务器）
+func (c *Config) TargetServers() []string {
+	if c.Server != "" {
+		return append([]string{c.Server}, c.Servers...)
+	}
+	return c.Servers
+}
+
+// TargetGroup 返回任务连接的服务器组
+func (c *Config) TargetGroup() string {
+	return c.ServerGroup
+}
+
+// TargetTag 返回任务选择服务器的标签
+func (c *Config) TargetTag() string {
+	return c.ServerTag
+}
+
+// Validate 验证 SSH 任务
+func (c *Config) Validate(v *config.TaskValidation) {
+	targets := 0
+	for _, set := range []bool{c.Server != "", len(c.Servers) > 0, c.ServerGroup != "", c.ServerTag != ""} {
+		if set {
+			targets++
+		}
+	}
+	switch {
+	case targets == 0:
+		v.AddError("server", "服务器名称不能为空（或使用 servers / server_group / server_tag 指定多台服务器）")
+	case targets > 1:
+		v.AddError("", "server、servers、server_group 与 server_tag 只能设置一个")
+	case c.Server != "":
+		if _, ok := v.Config().Servers[c.Server]; !ok {
+			v.AddError("server", fmt.Sprintf("服务器不存在: %s", c.Server))
+		}
+	case c.ServerGroup != "":
+		if !v.Config().HasGroup(c.ServerGroup) {
+			v.AddError("server_group", fmt.Sprintf("服务器组不存在: %s", c.ServerGroup))
+		}
+	case c.ServerTag != "":
+		if !v.Config().HasTag(c.ServerTag) {
+			v.AddError("server_tag", fmt.Sprintf("没有服务器设置该标签: %s", c.ServerTag))
+		}
+	default:
+		seen := make(map[string]bool)
+		for i, name := range c.Servers {
+			field := fmt.Sprintf("servers[%d]", i)
+			if _, ok := v.Config().Servers[name]; !ok {
+				v.AddError(field, fmt.Sprintf("服务器不存在: %s", name))
+			} else if seen[name] {
+				v.AddError(field, fmt.Sprintf("服务器重复: %s", name))
+			}
+			seen[name] = true
+		}
+	}
+
+	if c.Strategy != "" && !slices.Contains(Strategies, c.Strategy) {
+		v.AddError("strategy", fmt.Sprintf("无效的执行策略: %s (支持: %s)", c.Strategy, strings.Join(Strategies, ", ")))
+	}
+	switch {
+	case c.BatchSize < 0:
+		v.AddError("batch_size", "batch_size 不能为负数")
+	case c.BatchSize > 0 && c.strategy() != StrategyRolling:
+		v.AddError("batch_size", "batch_size 仅适用于 strategy: rolling")
+	}
+	if c.MaxFailures < 0 {
+		v.AddError("max_failures", "max_failures 不能为负数")
+	}
+	switch {
+	case c.Pause < 0:
+		v.AddError("pause", "pause 不能为负数")
+	case c.Pause > 0 && c.strategy() == StrategyParallel:
+		v.AddError("pause", "strategy: parallel 只有一个批次，不能设置 pause")
+	}
+
+	if len(c.Commands) == 0 && c.Script == "" && c.LocalScript == "" {
+		v.AddError("", "必须指定 commands、script 或 local_script")
+	}
+	for i, cmd := range c.Commands {
+		field := fmt.Sprintf("commands[%d]", i)
+		if strings.TrimSpace(cmd.Run) == "" {
+			v.AddError(field, "命令不能为空")
+		}
+		if cmd.Timeout < 0 {
+			v.AddError(field+".timeout", "timeout 不能为负数")
+		}
+	}
+	c.validateServerVariables(v)
+}
+
+// validateServerVariables 检查每台目标服务器都定义了命令中引用的服务器变量（${server.*}）
+func (c *Config) validateServerVariables(v *config.TaskValidation) {
+	fields := map[string]string{"script": c.Script}
+	for i, cmd := range c.Commands {
+		fields[fmt.Sprintf("commands[%d]", i)] = cmd.Run
+	}
+	for field, value := range fields {
+		if len(config.ServerVariableRefs(value)) == 0 {
+			continue
+		}
+		for _, name := range v.Config().TaskServers(c) {
+			if _, missing := config.ExpandServer(value, v.Config().ServerVariables(name)); len(missing) > 0 {
+				v.AddError(field, fmt.Sprintf("服务器 %s 未定义变量: %s", name, strings.Join(missing, ", ")))
+			}
+		}
+	}
+}
+
+// Expand 展开变量引用
+func (c *Config) Expand(expand func(string) string) {
+	c.Server = expand(c.Server)
+	for i, name := range c.Servers {
+		c.Servers[i] = expand(name)
+	}
+	c.ServerGroup = expand(c.ServerGroup)
+	c.ServerTag = expand(c.ServerTag)
+	c.Strategy = expand(c.Strategy)
+	for i := range c.Commands {
+		c.Commands[i].Run = expand(c.Commands[i].Run)
+	}
+	c.Script = expand(c.Script)
+	c.LocalScript = expand(c.LocalScript)
+}
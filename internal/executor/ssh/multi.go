@@ -0,0 +1,148 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+// executeHosts 按执行策略分批在多台服务器上执行，同一批次的服务器并发执行
+// 失败的服务器数超过 max_failures 时不再执行后续批次
+func (e *SSHExecutor) executeHosts(ctx context.Context, handler executor.OutputHandler) error {
+	batches := e.batches()
+	for _, host := range e.hosts {
+		e.reportHost(host.Name, executor.HostPending)
+	}
+
+	summary := fmt.Sprintf("🖥️  %d 台服务器（%s", len(e.hosts), e.strategy)
+	if e.strategy == StrategyRolling {
+		summary += fmt.Sprintf("，每批 %d 台", e.batchSize)
+	}
+	if e.maxFailures > 0 {
+		summary += fmt.Sprintf("，允许失败 %d 台", e.maxFailures)
+	}
+	handler(summary+"）: "+hostNames(e.hosts), false)
+	handler("", false)
+
+	var failed []string
+	done := 0
+	for i, batch := range batches {
+		if i > 0 && e.pause > 0 {
+			handler(fmt.Sprintf("⏸️  暂停 %s 后执行下一批", e.pause), false)
+			select {
+			case <-ctx.Done():
+				e.skipHosts(batches[i:])
+				return ctx.Err()
+			case <-time.After(e.pause):
+			}
+		}
+		if len(batches) > 1 {
+			handler(fmt.Sprintf("📦 [%d/%d] 批次: %s", i+1, len(batches), hostNames(batch)), false)
+		}
+
+		for j, err := range e.runBatch(ctx, batch, handler) {
+			done++
+			if err != nil {
+				failed = append(failed, batch[j].Name)
+				handler(fmt.Sprintf("❌ [%s] 执行失败: %v", batch[j].Name, err), true)
+			}
+		}
+		if ctx.Err() != nil {
+			e.skipHosts(batches[i+1:])
+			return ctx.Err()
+		}
+
+		if len(failed) > e.maxFailures {
+			if rest := len(e.hosts) - done; rest > 0 {
+				e.skipHosts(batches[i+1:])
+				handler(fmt.Sprintf("⛔ 失败的服务器数 %d 超过上限 %d，跳过剩余 %d 台服务器", len(failed), e.maxFailures, rest), true)
+			}
+			return fmt.Errorf("%d 台服务器执行失败: %s", len(failed), strings.Join(failed, ", "))
+		}
+		handler("", false)
+	}
+
+	if len(failed) > 0 {
+		handler(fmt.Sprintf("⚠️  [WARN] %d 台服务器执行失败（max_failures: %d）: %s", len(failed), e.maxFailures, strings.Join(failed, ", ")), true)
+	}
+	handler(fmt.Sprintf("✅ %d/%d 台服务器执行成功", len(e.hosts)-len(failed), len(e.hosts)), false)
+	return nil
+}
+
+// batches 按执行策略将服务器分批
+func (e *SSHExecutor) batches() [][]Host {
+	size := 1
+	switch e.strategy {
+	case StrategyParallel:
+		size = len(e.hosts)
+	case StrategyRolling:
+		size = e.batchSize
+	}
+
+	var batches [][]Host
+	for start := 0; start < len(e.hosts); start += size {
+		batches = append(batches, e.hosts[start:min(start+size, len(e.hosts))])
+	}
+	return batches
+}
+
+// runBatch 并发在一批服务器上执行，输出带服务器名称前缀，返回各服务器的执行结果
+func (e *SSHExecutor) runBatch(ctx context.Context, batch []Host, handler executor.OutputHandler) []error {
+	errs := make([]error, len(batch))
+	var wg sync.WaitGroup
+	for i, host := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.reportHost(host.Name, executor.HostRunning)
+			errs[i] = e.executeHost(ctx, host, prefixHandler(host.Name, handler))
+			if errs[i] != nil {
+				e.reportHost(host.Name, executor.HostFailed)
+			} else {
+				e.reportHost(host.Name, executor.HostSuccess)
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// skipHosts 将未执行的服务器标记为跳过
+func (e *SSHExecutor) skipHosts(batches [][]Host) {
+	for _, batch := range batches {
+		for _, host := range batch {
+			e.reportHost(host.Name, executor.HostSkipped)
+		}
+	}
+}
+
+// reportHost 上报服务器状态
+func (e *SSHExecutor) reportHost(name string, state executor.HostState) {
+	if e.hostStatus != nil {
+		e.hostStatus(name, state)
+	}
+}
+
+// prefixHandler 为输出加上服务器名称前缀，如 [web-1] Started
+func prefixHandler(name string, handler executor.OutputHandler) executor.OutputHandler {
+	return func(line string, isError bool) {
+		if line == "" {
+			handler("", isError)
+			return
+		}
+		handler(fmt.Sprintf("[%s] %s", name, line), isError)
+	}
+}
+
+// hostNames 返回服务器名称列表，如 web-1, web-2
+func hostNames(hosts []Host) string {
+	names := make([]string, len(hosts))
+	for i, host := range hosts {
+		names[i] = host.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,321 @@
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
+	"golang.org/x/crypto/ssh"
+)
+
+// executeCommands 在同一个远程 shell 中依次执行命令，工作目录与环境变量在命令之间保留
+// 每条命令单独记录退出码与耗时；设置了 ignore_error 的命令失败（含超时）后继续执行后续命令
+func (e *SSHExecutor) executeCommands(ctx context.Context, client *ssh.Client, commands []Command, handler executor.OutputHandler) error {
+	if len(commands) == 0 {
+		return nil
+	}
+
+	var (
+		shell   *remoteShell
+		ignored []string
+		err     error
+	)
+	defer func() {
+		if shell != nil {
+			shell.close()
+		}
+	}()
+
+	for i, cmd := range commands {
+		label := fmt.Sprintf("[%d/%d]", i+1, len(commands))
+		if shell == nil {
+			if i > 0 {
+				handler("⚠️  [WARN] 远程 shell 已退出，后续命令在新的 shell 中执行（之前的工作目录与环境变量不再保留）", true)
+			}
+			if shell, err = startShell(ctx, client, handler); err != nil {
+				return fmt.Errorf("启动远程 shell 失败: %w", err)
+			}
+		}
+
+		handler(fmt.Sprintf("📝 %s 执行: %s", label, cmd.Run), false)
+		start := time.Now()
+		result := shell.run(ctx, i, cmd)
+		elapsed := executor.FormatDuration(time.Since(start))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if result.exited {
+			shell.close()
+			shell = nil
+		}
+
+		var status string
+		switch {
+		case result.timedOut:
+			status = fmt.Sprintf("超时 %ds，已终止远程 shell", cmd.Timeout)
+		case result.code != 0:
+			status = fmt.Sprintf("退出码 %d，耗时 %s", result.code, elapsed)
+		default:
+			handler(fmt.Sprintf("✅ %s 完成（退出码 0，耗时 %s）", label, elapsed), false)
+			continue
+		}
+
+		if cmd.IgnoreError {
+			handler(fmt.Sprintf("⚠️  [WARN] %s 失败已忽略（%s）", label, status), true)
+			ignored = append(ignored, strconv.Itoa(i+1))
+			continue
+		}
+		handler(fmt.Sprintf("❌ %s 失败（%s）", label, status), true)
+		if rest := len(commands) - i - 1; rest > 0 {
+			handler(fmt.Sprintf("⏭️  跳过剩余 %d 条命令", rest), false)
+		}
+		if result.timedOut {
+			return fmt.Errorf("第 %d 条命令超时（%ds）: %s", i+1, cmd.Timeout, cmd.Run)
+		}
+		return fmt.Errorf("第 %d 条命令失败（退出码 %d）: %s", i+1, result.code, cmd.Run)
+	}
+
+	if len(ignored) > 0 {
+		handler(fmt.Sprintf("✅ %d 条命令执行完成（第 %s 条失败已忽略）", len(commands), strings.Join(ignored, "、")), false)
+	} else {
+		handler(fmt.Sprintf("✅ %d 条命令执行完成", len(commands)), false)
+	}
+	return nil
+}
+
+// shellMarkerPrefix 命令结束标记的前缀
+// stdout 标记为 <前缀><随机串>_<序号>:<退出码>，stderr 标记为 <前缀><随机串>_<序号>
+const shellMarkerPrefix = "__XBUILDER_DONE_"
+
+// shellStartCommand 启动远程 shell：优先使用 bash（兼容原先按登录 shell 执行的 bash 语法），否则使用 sh
+const shellStartCommand = "command -v bash >/dev/null 2>&1 && exec bash || exec sh"
+
+// stderrSyncTimeout 读到 stdout 结束标记后等待 stderr 结束标记的最长时间
+const stderrSyncTimeout = 5 * time.Second
+
+// remoteShell 远程持久 shell，通过 stdin 逐条写入命令，从输出中的结束标记获取退出码
+type remoteShell struct {
+	session *ssh.Session
+	stdin   io.WriteCloser
+	token   string
+	status  chan shellMark // stdout 中的结束标记
+	synced  chan shellMark // stderr 中的结束标记
+	done    chan struct{}  // shell 已退出且输出读取完毕
+	closed  chan struct{}  // 已调用 close
+	exitErr error
+	once    sync.Once
+}
+
+// shellMark 命令结束标记
+type shellMark struct {
+	seq  int
+	code int
+}
+
+// commandResult 单条命令的执行结果
+type commandResult struct {
+	code     int
+	timedOut bool // 命令超时，远程 shell 已终止
+	exited   bool // 远程 shell 已退出，后续命令需要新的 shell
+}
+
+// startShell 启动远程 shell，输出逐行交给 handler（结束标记除外）
+func startShell(ctx context.Context, client *ssh.Client, handler executor.OutputHandler) (*remoteShell, error) {
+	session, err := sshclient.NewSession(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("创建 SSH session 失败: %w", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := session.Start(shellStartCommand); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	s := &remoteShell{
+		session: session,
+		stdin:   stdin,
+		token:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		status:  make(chan shellMark, 1),
+		synced:  make(chan shellMark, 1),
+		done:    make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+
+	var output sync.WaitGroup
+	output.Add(2)
+	go func() {
+		defer output.Done()
+		s.readOutput(stdout, s.status, handler, false)
+	}()
+	go func() {
+		defer output.Done()
+		s.readOutput(stderr, s.synced, handler, true)
+	}()
+	go func() {
+		s.exitErr = session.Wait()
+		output.Wait()
+		close(s.done)
+	}()
+
+	return s, nil
+}
+
+// run 在 shell 中执行一条命令，等待其结束标记
+// 命令的 stdin 重定向为 /dev/null，避免读取后续写入 shell 的内容
+func (s *remoteShell) run(ctx context.Context, seq int, cmd Command) commandResult {
+	if cmd.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cmd.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	if _, err := io.WriteString(s.stdin, s.script(seq, cmd.Run)); err != nil {
+		return commandResult{code: s.wait(), exited: true}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.close()
+			return commandResult{code: -1, timedOut: true, exited: true}
+		case mark, ok := <-s.status:
+			if !ok {
+				// 命令中执行了 exit（或 set -e 时命令失败），以 shell 的退出码作为命令的退出码
+				return commandResult{code: s.wait(), exited: true}
+			}
+			if mark.seq != seq {
+				continue
+			}
+			s.syncStderr(seq)
+			return commandResult{code: mark.code}
+		}
+	}
+}
+
+// script 生成写入 shell 的内容：执行命令后输出结束标记
+// 命令以单引号字符串交给 eval 执行，未闭合的引号、heredoc 或 { 只会使这条命令因语法错误失败，不会吞掉后续写入的内容
+// command 避免 sh 在 eval 语法错误时退出；其后的空行重置 bash 在 eval 语法错误后残留的解析状态
+// 输出标记时临时关闭 set -x（之后恢复），标记由多个参数拼接，避免回显的命令被识别为标记
+func (s *remoteShell) script(seq int, command string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "command eval %s < /dev/null\n\n", sshclient.Quote(command))
+	b.WriteString("{ __xb_status=$?; case $- in *x*) __xb_xtrace=1 ;; *) __xb_xtrace= ;; esac; set +x; } 2>/dev/null\n")
+	fmt.Fprintf(&b, "printf '%%s%%s_%d:%%d\\n' %s %s \"$__xb_status\"\n", seq, shellMarkerPrefix, s.token)
+	fmt.Fprintf(&b, "printf '%%s%%s_%d\\n' %s %s >&2\n", seq, shellMarkerPrefix, s.token)
+	b.WriteString("[ -n \"$__xb_xtrace\" ] && set -x\n")
+	return b.String()
+}
+
+// syncStderr 等待 stderr 的结束标记，确保该命令的错误输出已全部读取
+func (s *remoteShell) syncStderr(seq int) {
+	timeout := time.After(stderrSyncTimeout)
+	for {
+		select {
+		case mark, ok := <-s.synced:
+			if !ok || mark.seq == seq {
+				return
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// readOutput 逐行读取输出，识别结束标记（标记前未换行的输出照常显示）
+func (s *remoteShell) readOutput(r io.Reader, marks chan<- shellMark, handler executor.OutputHandler, isError bool) {
+	defer close(marks)
+
+	marker := shellMarkerPrefix + s.token + "_"
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		consumed := false
+		if idx := strings.LastIndex(line, marker); idx >= 0 {
+			if mark, ok := parseShellMark(line[idx+len(marker):], isError); ok {
+				if idx > 0 {
+					handler(line[:idx], isError)
+				}
+				select {
+				case marks <- mark:
+				case <-s.closed:
+				}
+				consumed = true
+			}
+		}
+		if !consumed && (err == nil || line != "") {
+			handler(line, isError)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseShellMark 解析结束标记中的序号与退出码（stderr 标记只有序号）
+func parseShellMark(s string, isError bool) (shellMark, bool) {
+	seqText, codeText, hasCode := strings.Cut(s, ":")
+	seq, err := strconv.Atoi(seqText)
+	if err != nil || hasCode == isError {
+		return shellMark{}, false
+	}
+	if isError {
+		return shellMark{seq: seq}, true
+	}
+	code, err := strconv.Atoi(codeText)
+	if err != nil {
+		return shellMark{}, false
+	}
+	return shellMark{seq: seq, code: code}, true
+}
+
+// wait 等待 shell 退出，返回其退出码（无法获取时为 -1）
+func (s *remoteShell) wait() int {
+	<-s.done
+	var exitErr *ssh.ExitError
+	switch {
+	case s.exitErr == nil:
+		return 0
+	case errors.As(s.exitErr, &exitErr):
+		return exitErr.ExitStatus()
+	default:
+		return -1
+	}
+}
+
+// close 关闭 shell（命令仍在运行时先发送 SIGTERM）
+func (s *remoteShell) close() {
+	s.once.Do(func() {
+		close(s.closed)
+		s.stdin.Close()
+		select {
+		case <-s.done:
+		default:
+			s.session.Signal(ssh.SIGTERM)
+		}
+		s.session.Close()
+	})
+}
@@ -0,0 +1,295 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
+	"golang.org/x/crypto/ssh"
+)
+
+// Host 任务连接的一台服务器
+type Host struct {
+	Name   string
+	Server config.Server
+	Jumps  []config.Server   // 依次经过的跳板机
+	Vars   map[string]string // 服务器变量，替换命令中的 ${server.*}
+}
+
+// SSHExecutor SSH 远程执行器
+// 连接多台服务器时按 strategy 分批执行，输出带服务器名称前缀
+type SSHExecutor struct {
+	*executor.BaseExecutor
+	hosts       []Host
+	pool        *sshclient.Pool // 为 nil 时单独建立连接
+	prompt      executor.PromptFunc
+	hostStatus  executor.HostStatusFunc
+	commands    []Command
+	script      string
+	localScript string
+	strategy    string
+	batchSize   int
+	maxFailures int
+	pause       time.Duration
+}
+
+// NewSSHExecutor 创建 SSH 执行器
+func NewSSHExecutor(taskName string, cfg Config, hosts []Host, prompt executor.PromptFunc) (*SSHExecutor, error) {
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("没有需要连接的服务器")
+	}
+	e := &SSHExecutor{
+		BaseExecutor: executor.NewBaseExecutor(taskName, executor.TypeSSH),
+		hosts:        hosts,
+		prompt:       prompt,
+		commands:     cfg.Commands,
+		script:       cfg.Script,
+		localScript:  cfg.LocalScript,
+		strategy:     cfg.strategy(),
+		batchSize:    max(cfg.BatchSize, 1),
+		maxFailures:  cfg.MaxFailures,
+		pause:        time.Duration(cfg.Pause) * time.Second,
+	}
+
+	// 提前检查认证配置（如密钥文件不存在）
+	for _, host := range hosts {
+		if err := sshclient.CheckRoute(host.Server, host.Jumps); err != nil {
+			if len(hosts) > 1 {
+				return nil, fmt.Errorf("服务器 %s: %w", host.Name, err)
+			}
+			return nil, err
+		}
+	}
+
+	// 设置超时
+	if cfg.Timeout > 0 {
+		e.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
+	} else {
+		e.SetTimeout(10 * time.Minute) // SSH 默认 10 分钟
+	}
+
+	return e, nil
+}
+
+// SetPool 设置 SSH 连接池（复用其他任务建立的连接）
+func (e *SSHExecutor) SetPool(pool *sshclient.Pool) {
+	e.pool = pool
+}
+
+// SetHostStatus 设置多服务器执行时各服务器状态的上报
+func (e *SSHExecutor) SetHostStatus(hostStatus executor.HostStatusFunc) {
+	e.hostStatus = hostStatus
+}
+
+// Execute 执行 SSH 命令（连接与全部命令受任务 timeout 限制）
+func (e *SSHExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
+	ctx, cancel := context.WithTimeout(ctx, e.GetTimeout())
+	defer cancel()
+
+	var err error
+	if len(e.hosts) > 1 {
+		err = e.executeHosts(ctx, handler)
+	} else {
+		err = e.executeHost(ctx, e.hosts[0], handler)
+	}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("任务执行超时 (%v): %w", e.GetTimeout(), err)
+	}
+	return err
+}
+
+// executeHost 在一台服务器上执行
+func (e *SSHExecutor) executeHost(ctx context.Context, host Host, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("🔗 连接服务器: %s@%s", host.Server.Username, sshclient.Address(host.Server)), false)
+
+	// 连接服务器（优先复用连接池中的连接；经跳板机时逐跳连接；每一跳按 host_key_policy 校验主机密钥，需要时在 TUI 中输入密码短语）
+	client, release, err := sshclient.Connect(ctx, e.pool, host.Name, host.Server, sshclient.Options{
+		Log:    func(msg string) { handler(msg, false) },
+		Prompt: sshclient.PromptFunc(e.prompt),
+		Jumps:  host.Jumps,
+	})
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	handler("✅ SSH 连接成功", false)
+	handler("", false)
+
+	// 根据配置执行不同的操作
+	if e.localScript != "" {
+		return e.executeLocalScript(ctx, client, handler)
+	}
+
+	if e.script != "" {
+		script, err := host.expand(e.script)
+		if err != nil {
+			return err
+		}
+		return e.executeRemoteScript(ctx, client, script, handler)
+	}
+
+	commands := make([]Command, len(e.commands))
+	for i, cmd := range e.commands {
+		commands[i] = cmd
+		if commands[i].Run, err = host.expand(cmd.Run); err != nil {
+			return err
+		}
+	}
+	return e.executeCommands(ctx, client, commands, handler)
+}
+
+// expand 替换命令中的服务器变量（${server.*}）
+func (h Host) expand(s string) (string, error) {
+	result, missing := config.ExpandServer(s, h.Vars)
+	if len(missing) > 0 {
+		return "", fmt.Errorf("服务器 %s 未定义变量: %s", h.Name, strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+// executeRemoteScript 执行远程脚本
+func (e *SSHExecutor) executeRemoteScript(ctx context.Context, client *ssh.Client, script string, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("📜 执行远程脚本: %s", script), false)
+	return e.runCommand(ctx, client, script, handler)
+}
+
+// executeLocalScript 上传并执行本地脚本
+func (e *SSHExecutor) executeLocalScript(ctx context.Context, client *ssh.Client, handler executor.OutputHandler) error {
+	handler(fmt.Sprintf("📤 上传本地脚本: %s", e.localScript), false)
+
+	// 读取本地脚本
+	scriptContent, err := os.ReadFile(e.localScript)
+	if err != nil {
+		return fmt.Errorf("读取本地脚本失败: %w", err)
+	}
+
+	// 创建远程临时脚本
+	remotePath := "/tmp/xbuilder_script_" + fmt.Sprint(time.Now().UnixNano()) + ".sh"
+
+	// 创建 session 上传脚本
+	session, err := sshclient.NewSession(ctx, client)
+	if err != nil {
+		return fmt.Errorf("创建 SSH session 失败: %w", err)
+	}
+
+	// 使用 cat 写入脚本内容
+	uploadCmd := fmt.Sprintf("cat > %s << 'XBUILDER_EOF'\n%s\nXBUILDER_EOF", remotePath, string(scriptContent))
+	if err := session.Run(uploadCmd); err != nil {
+		session.Close()
+		return fmt.Errorf("上传脚本失败: %w", err)
+	}
+	session.Close()
+
+	// 添加执行权限
+	if err := e.runCommand(ctx, client, "chmod +x "+remotePath, handler); err != nil {
+		return fmt.Errorf("设置脚本权限失败: %w", err)
+	}
+
+	handler("✅ 脚本上传成功", false)
+	handler("📜 执行脚本...", false)
+	handler("", false)
+
+	// 执行脚本
+	if err := e.runCommand(ctx, client, remotePath, handler); err != nil {
+		return err
+	}
+
+	// 清理临时脚本
+	e.runCommand(ctx, client, "rm -f "+remotePath, nil)
+
+	return nil
+}
+
+// runCommand 运行单个命令
+func (e *SSHExecutor) runCommand(ctx context.Context, client *ssh.Client, command string, handler executor.OutputHandler) error {
+	session, err := sshclient.NewSession(ctx, client)
+	if err != nil {
+		return fmt.Errorf("创建 SSH session 失败: %w", err)
+	}
+	defer session.Close()
+
+	// 获取输出管道
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	// 启动命令
+	if err := session.Start(command); err != nil {
+		return err
+	}
+
+	// 创建完成通道
+	done := make(chan error, 1)
+
+	// 异步读取输出
+	var output sync.WaitGroup
+	output.Add(2)
+	go func() {
+		defer output.Done()
+		e.readOutput(stdout, handler, false)
+	}()
+	go func() {
+		defer output.Done()
+		e.readOutput(stderr, handler, true)
+	}()
+
+	// 等待命令完成（输出读取完毕后再返回，避免命令输出出现在后续日志之后）
+	go func() {
+		err := session.Wait()
+		output.Wait()
+		done <- err
+	}()
+
+	// 等待完成或取消
+	select {
+	case <-ctx.Done():
+		session.Signal(ssh.SIGTERM)
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+// readOutput 读取输出
+func (e *SSHExecutor) readOutput(r io.Reader, handler executor.OutputHandler, isError bool) {
+	if handler == nil {
+		return
+	}
+
+	buf := make([]byte, 4096)
+	var line strings.Builder
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			for i := 0; i < n; i++ {
+				if buf[i] == '\n' {
+					handler(line.String(), isError)
+					line.Reset()
+				} else {
+					line.WriteByte(buf[i])
+				}
+			}
+		}
+		if err != nil {
+			if line.Len() > 0 {
+				handler(line.String(), isError)
+			}
+			break
+		}
+	}
+}
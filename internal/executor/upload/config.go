@@ -0,0 +1,104 @@
+package upload
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+func init() {
+	executor.Register(executor.Definition{
+		Type:      executor.TypeUpload,
+		NewConfig: func() config.TaskSettings { return &Config{} },
+		New: func(rt *executor.Runtime, taskName string, settings config.TaskSettings) (executor.Executor, error) {
+			cfg := *settings.(*Config)
+			server, ok := rt.Config.Servers[cfg.Server]
+			if !ok {
+				return nil, fmt.Errorf("服务器不存在: %s", cfg.Server)
+			}
+			jumps, err := rt.Config.Route(cfg.Server)
+			if err != nil {
+				return nil, err
+			}
+			exec, err := NewUploadExecutor(taskName, cfg, server, jumps, rt.Prompt, rt.Progress)
+			if err != nil {
+				return nil, err
+			}
+			exec.SetPool(rt.SSHPool)
+			return exec, nil
+		},
+	})
+}
+
+// Config 文件上传任务配置
+type Config struct {
+	config.CommonConfig `yaml:",inline"`
+
+	Server        string   `yaml:"server,omitempty"`
+	Files         []string `yaml:"files,omitempty"`          // 本地文件、目录或 glob 模式
+	RemotePath    string   `yaml:"remote_path,omitempty"`    // 远程路径（以 / 结尾、或上传多个文件/目录/glob 时为目录）
+	Mode          string   `yaml:"mode,omitempty"`           // 文件权限（八进制，如 0755），默认与本地文件一致
+	Owner         string   `yaml:"owner,omitempty"`          // 文件属主（user 或 user:group）
+	SkipUnchanged bool     `yaml:"skip_unchanged,omitempty"` // 远程文件 sha256 一致时跳过
+}
+
+// TargetServers 返回任务连接的服务器
+func (c *Config) TargetServers() []string {
+	return []string{c.Server}
+}
+
+// Validate 验证上传任务（本地文件可能由之前的阶段生成，执行时再检查是否存在）
+func (c *Config) Validate(v *config.TaskValidation) {
+	if c.Server == "" {
+		v.AddError("server", "服务器名称不能为空")
+	} else if _, ok := v.Config().Servers[c.Server]; !ok {
+		v.AddError("server", fmt.Sprintf("服务器不存在: %s", c.Server))
+	}
+
+	if len(c.Files) == 0 {
+		v.AddError("files", "至少需要指定一个上传的文件")
+	}
+	for i, f := range c.Files {
+		if f == "" {
+			v.AddError(fmt.Sprintf("files[%d]", i), "文件路径不能为空")
+		} else if _, err := filepath.Match(f, ""); err != nil {
+			v.AddError(fmt.Sprintf("files[%d]", i), fmt.Sprintf("无效的 glob 模式: %s", f))
+		}
+	}
+
+	if c.RemotePath == "" {
+		v.AddError("remote_path", "远程路径不能为空")
+	}
+	if c.Mode != "" {
+		if _, err := parseMode(c.Mode); err != nil {
+			v.AddError("mode", err.Error())
+		}
+	}
+	if strings.ContainsAny(c.Owner, " \t") {
+		v.AddError("owner", fmt.Sprintf("无效的属主: %s（格式为 user 或 user:group）", c.Owner))
+	}
+}
+
+// Expand 展开变量引用
+func (c *Config) Expand(expand func(string) string) {
+	c.Server = expand(c.Server)
+	for i, f := range c.Files {
+		c.Files[i] = expand(f)
+	}
+	c.RemotePath = expand(c.RemotePath)
+	c.Mode = expand(c.Mode)
+	c.Owner = expand(c.Owner)
+}
+
+// parseMode 解析八进制文件权限
+func parseMode(mode string) (uint32, error) {
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || m > 0o7777 {
+		return 0, fmt.Errorf("无效的文件权限: %s（应为八进制，如 0644、0755）", mode)
+	}
+	return uint32(m), nil
+}
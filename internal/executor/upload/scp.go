@@ -0,0 +1,100 @@
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
+	"golang.org/x/crypto/ssh"
+)
+
+// scpUpload 通过 SCP 协议（远程执行 scp -t）将 r 中 size 字节写入远程文件 remote
+func scpUpload(ctx context.Context, client *ssh.Client, remote string, mode uint32, size int64, r io.Reader) error {
+	session, err := sshclient.NewSession(ctx, client)
+	if err != nil {
+		return fmt.Errorf("创建 SSH session 失败: %w", err)
+	}
+	defer session.Close()
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	session.Stderr = &stderr
+
+	if err := session.Start("scp -t " + sshclient.Quote(remote)); err != nil {
+		return fmt.Errorf("启动 scp 失败: %w", err)
+	}
+
+	err = scpSend(stdin, bufio.NewReader(stdout), remote, mode, size, r)
+	stdin.Close()
+	waitErr := session.Wait()
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w（%s）", err, msg)
+		}
+		return err
+	case waitErr != nil:
+		return fmt.Errorf("scp 异常退出: %w", waitErr)
+	}
+	return nil
+}
+
+// scpSend 按 SCP 协议发送单个文件：C<mode> <size> <name>，文件内容，\0，每一步等待远程确认
+func scpSend(w io.Writer, acks *bufio.Reader, remote string, mode uint32, size int64, r io.Reader) error {
+	if err := readAck(acks); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "C%04o %d %s\n", mode&0o7777, size, path.Base(remote)); err != nil {
+		return fmt.Errorf("发送文件信息失败: %w", err)
+	}
+	if err := readAck(acks); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(w, io.LimitReader(r, size))
+	if err != nil {
+		return fmt.Errorf("发送文件内容失败: %w", err)
+	}
+	if n != size {
+		return fmt.Errorf("文件在上传期间被修改（预期 %d 字节，实际读取 %d 字节）", size, n)
+	}
+	if _, err := w.Write([]byte{0}); err != nil {
+		return fmt.Errorf("发送文件内容失败: %w", err)
+	}
+	return readAck(acks)
+}
+
+// readAck 读取远程 scp 的确认：0 成功，1/2 为警告/错误并附带一行说明
+func readAck(r *bufio.Reader) error {
+	b, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("读取 scp 响应失败: %w", err)
+	}
+	switch b {
+	case 0:
+		return nil
+	case 1, 2:
+		msg, _ := r.ReadString('\n')
+		return fmt.Errorf("scp: %s", strings.TrimSpace(msg))
+	default:
+		// 远程没有 scp 时，shell 的错误信息会出现在这里
+		line, _ := r.ReadString('\n')
+		return fmt.Errorf("scp 响应异常: %s", strings.TrimSpace(string(b)+line))
+	}
+}
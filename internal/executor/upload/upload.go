@@ -0,0 +1,390 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
+	"golang.org/x/crypto/ssh"
+)
+
+// UploadExecutor 文件上传执行器
+// 文件先通过 SCP 写入同目录下的临时文件，设置权限/属主后再重命名，远程不会出现写了一半的文件
+type UploadExecutor struct {
+	*executor.BaseExecutor
+	serverName    string
+	server        config.Server
+	jumps         []config.Server // 依次经过的跳板机
+	pool          *sshclient.Pool // 为 nil 时单独建立连接
+	prompt        executor.PromptFunc
+	progress      executor.ProgressFunc
+	files         []string
+	remotePath    string
+	mode          uint32 // 0 表示与本地文件一致
+	owner         string
+	skipUnchanged bool
+}
+
+// transfer 单个待上传的文件
+type transfer struct {
+	local  string
+	remote string
+	size   int64
+	mode   uint32 // 本地文件权限
+}
+
+// NewUploadExecutor 创建文件上传执行器
+func NewUploadExecutor(taskName string, cfg Config, server config.Server, jumps []config.Server, prompt executor.PromptFunc, progress executor.ProgressFunc) (*UploadExecutor, error) {
+	e := &UploadExecutor{
+		BaseExecutor:  executor.NewBaseExecutor(taskName, executor.TypeUpload),
+		serverName:    cfg.Server,
+		server:        server,
+		jumps:         jumps,
+		prompt:        prompt,
+		progress:      progress,
+		files:         cfg.Files,
+		remotePath:    remoteRelative(cfg.RemotePath),
+		owner:         cfg.Owner,
+		skipUnchanged: cfg.SkipUnchanged,
+	}
+	if cfg.Mode != "" {
+		mode, err := parseMode(cfg.Mode)
+		if err != nil {
+			return nil, err
+		}
+		e.mode = mode
+	}
+
+	// 提前检查认证配置（如密钥文件不存在）
+	if err := sshclient.CheckRoute(server, jumps); err != nil {
+		return nil, err
+	}
+
+	if cfg.Timeout > 0 {
+		e.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
+	} else {
+		e.SetTimeout(30 * time.Minute) // 上传默认 30 分钟
+	}
+
+	return e, nil
+}
+
+// SetPool 设置 SSH 连接池（复用其他任务建立的连接）
+func (e *UploadExecutor) SetPool(pool *sshclient.Pool) {
+	e.pool = pool
+}
+
+// Execute 上传文件
+func (e *UploadExecutor) Execute(ctx context.Context, handler executor.OutputHandler) error {
+	ctx, cancel := context.WithTimeout(ctx, e.GetTimeout())
+	defer cancel()
+
+	transfers, err := e.resolve()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, t := range transfers {
+		total += t.size
+	}
+	handler(fmt.Sprintf("📦 待上传 %d 个文件，共 %s", len(transfers), executor.FormatBytes(total)), false)
+
+	handler(fmt.Sprintf("🔗 连接服务器: %s@%s", e.server.Username, sshclient.Address(e.server)), false)
+	client, release, err := sshclient.Connect(ctx, e.pool, e.serverName, e.server, sshclient.Options{
+		Log:    func(msg string) { handler(msg, false) },
+		Prompt: sshclient.PromptFunc(e.prompt),
+		Jumps:  e.jumps,
+	})
+	if err != nil {
+		return err
+	}
+	defer release()
+	handler("✅ SSH 连接成功", false)
+
+	if err := e.resolveTarget(ctx, client, transfers); err != nil {
+		return err
+	}
+	if err := e.makeDirs(ctx, client, transfers); err != nil {
+		return err
+	}
+
+	progress := executor.NewByteProgress(e.progress, total)
+	var sent int64
+	skipped := 0
+	for i, t := range transfers {
+		step := fmt.Sprintf("[%d/%d]", i+1, len(transfers))
+
+		if e.skipUnchanged && e.unchanged(ctx, client, t, handler) {
+			handler(fmt.Sprintf("⏭️  %s 未变化，跳过: %s", step, t.remote), false)
+			// 内容一致时仍按配置修正权限与属主
+			if cmd := e.attrCommand(t.remote, t, e.mode != 0); cmd != "" {
+				if _, err := sshclient.Output(ctx, client, cmd); err != nil {
+					return fmt.Errorf("设置 %s 的权限/属主失败: %w", t.remote, err)
+				}
+			}
+			progress.Add(t.size)
+			skipped++
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		handler(fmt.Sprintf("📤 %s %s → %s (%s)", step, t.local, t.remote, executor.FormatBytes(t.size)), false)
+		if err := e.upload(ctx, client, t, progress); err != nil {
+			return fmt.Errorf("上传 %s 失败: %w", t.local, err)
+		}
+		sent += t.size
+	}
+
+	msg := fmt.Sprintf("✅ 上传完成: %d 个文件，共传输 %s", len(transfers)-skipped, executor.FormatBytes(sent))
+	if skipped > 0 {
+		msg += fmt.Sprintf("（%d 个未变化的文件已跳过）", skipped)
+	}
+	handler(msg, false)
+	return nil
+}
+
+// resolve 展开本地文件、目录与 glob，计算各文件的远程路径
+// remote_path 以 / 结尾、或 files 中有多项、glob 或目录时视为目录，否则为目标文件路径
+func (e *UploadExecutor) resolve() ([]transfer, error) {
+	toDir := strings.HasSuffix(e.remotePath, "/") || len(e.files) > 1
+
+	type source struct {
+		path string
+		info fs.FileInfo
+	}
+	var sources []source
+	for _, pattern := range e.files {
+		paths := []string{pattern}
+		if hasMeta(pattern) {
+			toDir = true
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 glob 模式 %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("没有匹配的文件: %s", pattern)
+			}
+			paths = matches
+		}
+		for _, p := range paths {
+			info, err := os.Stat(p)
+			if err != nil {
+				return nil, fmt.Errorf("读取本地文件失败: %w", err)
+			}
+			if info.IsDir() {
+				toDir = true
+			}
+			sources = append(sources, source{path: p, info: info})
+		}
+	}
+
+	var transfers []transfer
+	targets := make(map[string]string)
+	add := func(local, rel string, info fs.FileInfo) error {
+		remote := e.remotePath
+		if toDir {
+			remote = path.Join(e.remotePath, filepath.ToSlash(rel))
+		}
+		if prev, ok := targets[remote]; ok {
+			return fmt.Errorf("%s 与 %s 上传到同一远程路径: %s", prev, local, remote)
+		}
+		targets[remote] = local
+		transfers = append(transfers, transfer{
+			local:  local,
+			remote: remote,
+			size:   info.Size(),
+			mode:   uint32(info.Mode().Perm()),
+		})
+		return nil
+	}
+
+	for _, src := range sources {
+		if !src.info.IsDir() {
+			if err := add(src.path, filepath.Base(src.path), src.info); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// 目录按 scp -r 的方式上传到 remote_path/<目录名>/ 下（跳过非普通文件）
+		base := filepath.Base(src.path)
+		err := filepath.WalkDir(src.path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := os.Stat(p)
+			if err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(src.path, p)
+			if err != nil {
+				return err
+			}
+			return add(p, filepath.Join(base, rel), info)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("读取目录 %s 失败: %w", src.path, err)
+		}
+	}
+
+	if len(transfers) == 0 {
+		return nil, fmt.Errorf("没有需要上传的文件")
+	}
+	return transfers, nil
+}
+
+// resolveTarget 单个文件上传且 remote_path 是已存在的目录时，与 scp 一致上传到该目录下
+// 须在比较校验和与上传之前调用，否则会把目录当作目标文件处理
+func (e *UploadExecutor) resolveTarget(ctx context.Context, client *ssh.Client, transfers []transfer) error {
+	if len(transfers) != 1 || transfers[0].remote != e.remotePath {
+		return nil
+	}
+	q := sshclient.Quote(e.remotePath)
+	out, err := sshclient.Output(ctx, client, fmt.Sprintf("if [ -d %s ]; then echo dir; fi", q))
+	if err != nil {
+		return fmt.Errorf("检查远程路径 %s 失败: %w", e.remotePath, err)
+	}
+	if strings.TrimSpace(out) == "dir" {
+		transfers[0].remote = path.Join(e.remotePath, filepath.Base(transfers[0].local))
+	}
+	return nil
+}
+
+// makeDirs 创建远程目录
+func (e *UploadExecutor) makeDirs(ctx context.Context, client *ssh.Client, transfers []transfer) error {
+	var dirs []string
+	seen := make(map[string]bool)
+	for _, t := range transfers {
+		dir := path.Dir(t.remote)
+		if dir == "." || dir == "/" || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, sshclient.Quote(dir))
+	}
+	if len(dirs) == 0 {
+		return nil
+	}
+	if _, err := sshclient.Output(ctx, client, "mkdir -p -- "+strings.Join(dirs, " ")); err != nil {
+		return fmt.Errorf("创建远程目录失败: %w", err)
+	}
+	return nil
+}
+
+// unchanged 比较本地与远程文件的 sha256，无法比较时视为已变化
+func (e *UploadExecutor) unchanged(ctx context.Context, client *ssh.Client, t transfer, handler executor.OutputHandler) bool {
+	local, err := fileSHA256(t.local)
+	if err != nil {
+		return false
+	}
+
+	q := sshclient.Quote(t.remote)
+	out, err := sshclient.Output(ctx, client,
+		fmt.Sprintf("if [ -f %[1]s ]; then sha256sum %[1]s 2>/dev/null || shasum -a 256 %[1]s; fi", q))
+	if err != nil {
+		if ctx.Err() == nil {
+			handler(fmt.Sprintf("⚠️  [WARN] 无法计算远程文件 %s 的校验和，重新上传: %v", t.remote, err), false)
+		}
+		return false
+	}
+	fields := strings.Fields(out)
+	return len(fields) > 0 && strings.EqualFold(fields[0], local)
+}
+
+// upload 上传到临时文件，设置权限/属主后重命名为目标文件；失败时清理临时文件
+func (e *UploadExecutor) upload(ctx context.Context, client *ssh.Client, t transfer, progress *executor.ByteProgress) error {
+	f, err := os.Open(t.local)
+	if err != nil {
+		return fmt.Errorf("读取本地文件失败: %w", err)
+	}
+	defer f.Close()
+
+	tmp := path.Join(path.Dir(t.remote), fmt.Sprintf(".%s.xbuilder-%d.tmp", path.Base(t.remote), time.Now().UnixNano()))
+	cleanup := func() {
+		// 任务取消时也要清理临时文件
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		sshclient.Output(cleanupCtx, client, "rm -f -- "+sshclient.Quote(tmp))
+	}
+
+	if err := scpUpload(ctx, client, tmp, e.fileMode(t), t.size, progress.Reader(f)); err != nil {
+		cleanup()
+		return err
+	}
+
+	// 目标是已存在的目录时 mv 会把文件移入该目录，这里直接报错
+	target := sshclient.Quote(t.remote)
+	cmd := fmt.Sprintf("if [ -d %[1]s ]; then echo '远程路径是已存在的目录: '%[1]s >&2; exit 1; fi && ", target) +
+		e.attrCommand(tmp, t, true) + " && mv -f -- " + sshclient.Quote(tmp) + " " + target
+	if _, err := sshclient.Output(ctx, client, cmd); err != nil {
+		cleanup()
+		return fmt.Errorf("替换远程文件失败: %w", err)
+	}
+	return nil
+}
+
+// attrCommand 返回设置文件权限（withMode 为 true 时）与属主的命令，无需设置时返回空字符串
+func (e *UploadExecutor) attrCommand(target string, t transfer, withMode bool) string {
+	var cmds []string
+	if withMode {
+		cmds = append(cmds, fmt.Sprintf("chmod %04o %s", e.fileMode(t), sshclient.Quote(target)))
+	}
+	if e.owner != "" {
+		cmds = append(cmds, fmt.Sprintf("chown %s %s", sshclient.Quote(e.owner), sshclient.Quote(target)))
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// fileMode 返回远程文件权限：配置的 mode，否则与本地文件一致
+func (e *UploadExecutor) fileMode(t transfer) uint32 {
+	if e.mode != 0 {
+		return e.mode
+	}
+	return t.mode
+}
+
+// fileSHA256 计算本地文件的 sha256
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hasMeta 检查路径是否包含 glob 通配符
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// remoteRelative 将 ~/ 开头的远程路径转为相对路径（SSH 会话的工作目录即 home 目录）
+func remoteRelative(p string) string {
+	switch {
+	case p == "~" || p == "~/":
+		return "./"
+	case strings.HasPrefix(p, "~/"):
+		return p[2:]
+	}
+	return p
+}
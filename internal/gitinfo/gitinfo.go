@@ -0,0 +1,141 @@
+// Package gitinfo 读取构建目录所在 git 仓库的提交、分支与远程地址
+package gitinfo
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Info git 仓库信息
+type Info struct {
+	Commit        string // 完整提交哈希
+	ShortCommit   string // 短提交哈希（12 位）
+	Branch        string // 当前分支，分离 HEAD 时为空
+	DefaultBranch string // 默认分支（origin/HEAD 指向的分支，未设置时回退到 main/master）
+	RemoteURL     string // origin 远程地址（已规范化为 https 并去除凭据）
+	Dirty         bool   // 工作区是否有未提交的修改
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]*Info)
+)
+
+// Detect 读取 dir 所在仓库的信息，同一目录的结果会被缓存
+// dir 不在 git 仓库中时返回错误
+func Detect(dir string) (*Info, error) {
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if info, ok := cache[abs]; ok {
+		return info, nil
+	}
+
+	commit, err := git(abs, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("读取 git 提交失败: %w", err)
+	}
+
+	info := &Info{Commit: commit, ShortCommit: commit}
+	if len(commit) > 12 {
+		info.ShortCommit = commit[:12]
+	}
+	if branch, err := git(abs, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		info.Branch = branch
+	}
+	info.DefaultBranch = defaultBranch(abs)
+	if remote, err := git(abs, "config", "--get", "remote.origin.url"); err == nil {
+		info.RemoteURL = NormalizeRemote(remote)
+	}
+	if status, err := git(abs, "status", "--porcelain"); err == nil {
+		info.Dirty = status != ""
+	}
+
+	cache[abs] = info
+	return info, nil
+}
+
+// IsDefaultBranch 返回当前是否位于默认分支
+func (i *Info) IsDefaultBranch() bool {
+	return i.Branch != "" && i.Branch == i.DefaultBranch
+}
+
+// defaultBranch 返回仓库的默认分支
+func defaultBranch(dir string) string {
+	if ref, err := git(dir, "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(ref, "origin/")
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := git(dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// NormalizeRemote 将 git 远程地址规范化为可浏览的 https 地址
+// git@host:owner/repo.git、ssh://git@host/owner/repo.git → https://host/owner/repo；
+// https 地址中的用户名与令牌会被移除
+func NormalizeRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(remote, "ssh://"), strings.HasPrefix(remote, "git://"),
+		strings.HasPrefix(remote, "http://"), strings.HasPrefix(remote, "https://"):
+		scheme, rest, _ := strings.Cut(remote, "://")
+		if at := strings.LastIndex(rest, "@"); at >= 0 && at < strings.Index(rest+"/", "/") {
+			rest = rest[at+1:]
+		}
+		if scheme == "ssh" || scheme == "git" {
+			// 去除 ssh 端口号
+			host, path, _ := strings.Cut(rest, "/")
+			if i := strings.Index(host, ":"); i >= 0 {
+				host = host[:i]
+			}
+			rest = host + "/" + path
+			scheme = "https"
+		}
+		remote = scheme + "://" + rest
+	case strings.Contains(remote, ":") && !strings.Contains(remote, "://"):
+		// scp 风格: [user@]host:owner/repo.git
+		host, path, _ := strings.Cut(remote, ":")
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		remote = "https://" + host + "/" + strings.TrimPrefix(path, "/")
+	default:
+		// 本地路径等无法浏览的地址
+		return remote
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+}
+
+// git 在 dir 中执行 git 命令并返回去除首尾空白的输出
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -106,16 +106,13 @@ func (p *Pipeline) newTaskOutputHandler(task *Task) (executor.OutputHandler, fun
 		p.sendMsg(types.NewOutputMsg(task.ID, line, isError))
 	}
 
-	// 仅对 Docker 构建 / SSH 远程支持可选“强制降级刷新”（减少消息频率，避免刷屏）
-	if task.Type != config.TaskTypeDockerBuild && task.Type != config.TaskTypeSSH {
-		return sendLine, func() {}
-	}
-
+	// 仅对支持 force_refresh 的任务类型（Docker 构建 / SSH 远程）做可选“强制降级刷新”
 	// force_refresh=true：启用降级（批量）以避免刷屏；否则保持逐行实时输出
-	if !task.Config.ForceRefresh {
+	batcher, ok := task.Settings.(config.OutputBatcher)
+	if !ok || !batcher.BatchOutput() {
 		return sendLine, func() {}
 	}
 
-	batcher := newOutputBatcher(task.ID, p.sendMsg)
-	return batcher.handle, batcher.stop
+	b := newOutputBatcher(task.ID, p.sendMsg)
+	return b.handle, b.stop
 }
@@ -3,12 +3,15 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/xiaolfeng/builder-cli/internal/config"
 	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/sshclient"
 	"github.com/xiaolfeng/builder-cli/internal/types"
 )
 
@@ -16,28 +19,45 @@ import (
 type Pipeline struct {
 	config       *config.Config
 	stages       []*Stage
-	program      *tea.Program    // 用于向 TUI 发送消息
-	builtImages  []string        // 记录已构建的镜像
-	pushedImages map[string]bool // 记录已推送的镜像
+	program      *tea.Program                      // 用于向 TUI 发送消息
+	builtImages  []string                          // 记录已构建的镜像
+	pushedImages map[string]bool                   // 记录已推送的镜像
+	exports      map[string][]executor.ImageExport // 记录已导出到文件的镜像
+	images       []ImageSummary                    // 运行摘要中的镜像
+	runtimeVars  map[string]string                 // 运行时变量（如 images.api.digest）
+	runID        string                            // 本次运行 ID
+	manifestPath string                            // images.json 路径
+	manifestErr  error                             // 写入 images.json 失败的原因
+	session      *executor.Session                 // 运行会话，所有执行器共享
+	sshPool      *sshclient.Pool                   // SSH 连接池，跨任务、阶段复用连接，运行结束时关闭
+	promptMu     sync.Mutex                        // 同一时间只显示一个输入框
 	mu           sync.RWMutex
 }
 
 // New 创建新的流水线
-func New(cfg *config.Config) *Pipeline {
+func New(cfg *config.Config) (*Pipeline, error) {
 	p := &Pipeline{
 		config:       cfg,
 		stages:       make([]*Stage, 0, len(cfg.Pipeline)),
 		builtImages:  make([]string, 0),
 		pushedImages: make(map[string]bool),
+		exports:      make(map[string][]executor.ImageExport),
+		runtimeVars:  make(map[string]string),
+		runID:        time.Now().Format("20060102-150405"),
+		session:      executor.NewSession(),
+		sshPool:      sshclient.NewPool(),
 	}
 
 	// 创建阶段
 	for i, stageCfg := range cfg.Pipeline {
-		stage := NewStage(i, stageCfg, cfg)
+		stage, err := NewStage(i, stageCfg)
+		if err != nil {
+			return nil, fmt.Errorf("阶段 [%s] 创建失败: %w", stageCfg.Name, err)
+		}
 		p.stages = append(p.stages, stage)
 	}
 
-	return p
+	return p, nil
 }
 
 // SetProgram 设置 tea.Program 用于发送消息
@@ -84,6 +104,7 @@ func (p *Pipeline) Run(ctx context.Context) error {
 		if err != nil {
 			// 发送阶段失败消息
 			p.sendMsg(types.NewStageCompleteMsg(i, stage.Name, false, stageDuration))
+			p.finish()
 			// 发送流水线失败消息
 			p.sendMsg(types.NewPipelineCompleteMsg(false, time.Since(startTime), err))
 			return fmt.Errorf("阶段 [%s] 执行失败: %w", stage.Name, err)
@@ -93,12 +114,24 @@ func (p *Pipeline) Run(ctx context.Context) error {
 		p.sendMsg(types.NewStageCompleteMsg(i, stage.Name, true, stageDuration))
 	}
 
+	p.finish()
+
 	// 发送流水线完成消息
 	p.sendMsg(types.NewPipelineCompleteMsg(true, time.Since(startTime), nil))
 
 	return nil
 }
 
+// finish 运行结束时关闭 SSH 连接并写入镜像清单（失败时也写入已完成的部分）
+func (p *Pipeline) finish() {
+	p.sshPool.Close()
+	if err := p.writeManifest(); err != nil {
+		p.mu.Lock()
+		p.manifestErr = err
+		p.mu.Unlock()
+	}
+}
+
 // runStageSequential 顺序执行阶段中的任务
 func (p *Pipeline) runStageSequential(ctx context.Context, stage *Stage) error {
 	for _, task := range stage.Tasks {
@@ -148,6 +181,13 @@ func (p *Pipeline) runTask(ctx context.Context, task *Task) error {
 	handler, flush := p.newTaskOutputHandler(task)
 	defer flush()
 
+	// 替换运行时变量（如此前推送的镜像摘要）
+	if err := p.applyRuntimeVariables(task); err != nil {
+		p.sendMsg(types.NewTaskStatusMsg(task.ID, types.StatusFailed))
+		p.sendMsg(types.NewErrorMsg(task.ID, err, "运行时变量解析失败"))
+		return err
+	}
+
 	// 获取执行器
 	exec, err := p.createExecutor(task)
 	if err != nil {
@@ -164,21 +204,16 @@ func (p *Pipeline) runTask(ctx context.Context, task *Task) error {
 		return err
 	}
 
-	flush()
-
-	// 记录构建的镜像（用于 docker push）
-	if task.Type == config.TaskTypeDockerBuild {
-		if dockerExec, ok := exec.(*executor.DockerBuildExecutor); ok {
-			imageName := dockerExec.FullImageName()
-			p.mu.Lock()
-			p.builtImages = append(p.builtImages, imageName)
-			// 记录是否已在构建阶段推送
-			if dockerExec.IsPushed() {
-				p.pushedImages[imageName] = true
-			}
-			p.mu.Unlock()
-		}
+	// 记录构建的镜像（用于 docker push 与运行摘要）
+	if builder, ok := exec.(executor.ImageBuilder); ok {
+		p.recordImage(ctx, task, builder, exec, handler)
 	}
+	// 记录推送的镜像摘要（供后续任务通过 ${images.<名称>.digest} 引用）
+	if pusher, ok := exec.(executor.ImagePusher); ok {
+		p.recordPushed(task, pusher.PushedImages(), handler)
+	}
+
+	flush()
 
 	// 发送任务完成消息
 	p.sendMsg(types.NewTaskStatusMsg(task.ID, types.StatusSuccess))
@@ -186,51 +221,67 @@ func (p *Pipeline) runTask(ctx context.Context, task *Task) error {
 	return nil
 }
 
-// createExecutor 根据任务类型创建执行器
-func (p *Pipeline) createExecutor(task *Task) (executor.Executor, error) {
-	switch task.Type {
-	case config.TaskTypeMaven:
-		return executor.NewMavenExecutor(task.Name, task.Config), nil
-
-	case config.TaskTypeDockerBuild:
-		return executor.NewDockerBuildExecutor(task.Name, task.Config), nil
-
-	case config.TaskTypeDockerPush:
-		// 获取 registry 配置
-		reg, ok := p.config.Registries[task.Config.Registry]
-		if !ok {
-			return nil, fmt.Errorf("Registry 不存在: %s", task.Config.Registry)
-		}
-
-		exec := executor.NewDockerPushExecutor(task.Name, task.Config, &reg)
-
-		// 如果启用了 auto，使用已构建的镜像
-		if task.Config.Auto {
-			p.mu.RLock()
-			exec.SetImages(p.builtImages)
-			// 传递已推送的镜像状态，让 push 执行器跳过这些镜像
-			exec.SetSkipPushedImages(p.pushedImages)
-			p.mu.RUnlock()
-		}
-
-		return exec, nil
-
-	case config.TaskTypeSSH:
-		// 获取服务器配置
-		server, ok := p.config.Servers[task.Config.Server]
-		if !ok {
-			return nil, fmt.Errorf("服务器不存在: %s", task.Config.Server)
+// applyRuntimeVariables 替换任务配置中的运行时变量，引用尚未确定的变量时报错
+func (p *Pipeline) applyRuntimeVariables(task *Task) error {
+	var missing []string
+	task.Settings.Expand(func(s string) string {
+		result, unresolved := config.ExpandRuntime(s, p.lookupRuntimeVar)
+		for _, ref := range unresolved {
+			if !slices.Contains(missing, ref) {
+				missing = append(missing, ref)
+			}
 		}
-		return executor.NewSSHExecutor(task.Name, task.Config, &server)
+		return result
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("运行时变量尚未确定: %s（对应镜像需由之前阶段的任务推送）", strings.Join(missing, ", "))
+	}
+	return nil
+}
 
-	case config.TaskTypeGoBuild:
-		return executor.NewGoBuildExecutor(task.Name, task.Config), nil
+// createExecutor 根据任务类型创建执行器
+func (p *Pipeline) createExecutor(task *Task) (executor.Executor, error) {
+	// 复制运行时状态，避免执行器持有流水线内部的切片和映射
+	p.mu.RLock()
+	rt := &executor.Runtime{
+		Config:       p.config,
+		BuiltImages:  append([]string(nil), p.builtImages...),
+		PushedImages: make(map[string]bool, len(p.pushedImages)),
+		Exports:      make(map[string][]executor.ImageExport, len(p.exports)),
+		Session:      p.session,
+		SSHPool:      p.sshPool,
+		Prompt:       p.promptFor(task),
+		Progress: func(current, total int, message string) {
+			p.sendMsg(types.NewTaskProgressMsg(task.ID, current, total, message))
+		},
+		HostStatus: func(host string, state executor.HostState) {
+			p.sendMsg(types.NewTaskHostStatusMsg(task.ID, host, hostTaskStatus(state)))
+		},
+	}
+	for image, pushed := range p.pushedImages {
+		rt.PushedImages[image] = pushed
+	}
+	for image, exports := range p.exports {
+		rt.Exports[image] = exports
+	}
+	p.mu.RUnlock()
 
-	case config.TaskTypeShell:
-		return executor.NewShellExecutor(task.Name, task.Config), nil
+	return executor.Create(rt, task.Name, task.Type, task.Settings)
+}
 
+// hostTaskStatus 将服务器状态转换为任务队列中显示的状态
+func hostTaskStatus(state executor.HostState) types.TaskStatus {
+	switch state {
+	case executor.HostRunning:
+		return types.StatusRunning
+	case executor.HostSuccess:
+		return types.StatusSuccess
+	case executor.HostFailed:
+		return types.StatusFailed
+	case executor.HostSkipped:
+		return types.StatusSkipped
 	default:
-		return nil, fmt.Errorf("不支持的任务类型: %s", task.Type)
+		return types.StatusPending
 	}
 }
 
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+	"github.com/xiaolfeng/builder-cli/internal/types"
+)
+
+// errPromptCancelled 用户取消了输入
+var errPromptCancelled = errors.New("已取消输入")
+
+// promptFor 返回任务的交互式输入函数，没有 TUI 时返回 nil（需要输入的操作直接失败）
+func (p *Pipeline) promptFor(task *Task) executor.PromptFunc {
+	if p.program == nil {
+		return nil
+	}
+	return func(ctx context.Context, question string, secret bool) (string, error) {
+		return p.prompt(ctx, task, question, secret)
+	}
+}
+
+// prompt 在 TUI 中弹出输入框并等待输入；多个任务同时请求时依次显示
+func (p *Pipeline) prompt(ctx context.Context, task *Task, question string, secret bool) (string, error) {
+	p.promptMu.Lock()
+	defer p.promptMu.Unlock()
+
+	reply := make(chan types.PromptReply, 1)
+	p.sendMsg(types.PromptMsg{
+		TaskID:   task.ID,
+		TaskName: task.Name,
+		Question: question,
+		Secret:   secret,
+		Reply:    reply,
+	})
+
+	select {
+	case r := <-reply:
+		if r.Cancelled {
+			return "", errPromptCancelled
+		}
+		return r.Value, nil
+	case <-ctx.Done():
+		p.sendMsg(types.PromptCancelMsg{Reply: reply})
+		return "", ctx.Err()
+	}
+}
@@ -1,7 +1,10 @@
 package pipeline
 
 import (
+	"fmt"
+
 	"github.com/xiaolfeng/builder-cli/internal/config"
+	"github.com/xiaolfeng/builder-cli/internal/executor"
 )
 
 // Stage 流水线阶段
@@ -14,7 +17,8 @@ type Stage struct {
 }
 
 // NewStage 创建新的阶段
-func NewStage(index int, cfg config.Stage, fullCfg *config.Config) *Stage {
+// 任务配置在此按类型解码，支持展开的任务（如 docker-build 的 auto_scan）被展开为多个子任务
+func NewStage(index int, cfg config.Stage) (*Stage, error) {
 	s := &Stage{
 		Index:    index,
 		ID:       cfg.Stage,
@@ -24,12 +28,23 @@ func NewStage(index int, cfg config.Stage, fullCfg *config.Config) *Stage {
 	}
 
 	// 创建任务
-	for i, taskCfg := range cfg.Tasks {
-		task := NewTask(index, i, taskCfg)
-		s.Tasks = append(s.Tasks, task)
+	for _, taskCfg := range cfg.Tasks {
+		settings, err := taskCfg.Settings()
+		if err != nil {
+			return nil, fmt.Errorf("任务 [%s] 配置错误: %w", taskCfg.Name, err)
+		}
+
+		subTasks, err := executor.ExpandTask(taskCfg.Name, taskCfg.Type, settings)
+		if err != nil {
+			return nil, fmt.Errorf("任务 [%s] 展开失败: %w", taskCfg.Name, err)
+		}
+
+		for _, sub := range subTasks {
+			s.Tasks = append(s.Tasks, NewTask(index, len(s.Tasks), sub.Name, taskCfg.Type, sub.Settings))
+		}
 	}
 
-	return s
+	return s, nil
 }
 
 // GetTaskCount 获取任务数量
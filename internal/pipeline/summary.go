@@ -0,0 +1,267 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/executor"
+)
+
+// inspectTimeout 构建后查询镜像的超时时间
+const inspectTimeout = 30 * time.Second
+
+// ImageSummary 一次构建（或推送）产生的镜像
+type ImageSummary struct {
+	Task      string                 // 构建任务名称
+	Images    []string               // 镜像名称（主标签在前）
+	ID        string                 // 本地镜像 ID，多平台构建时为空
+	Labels    map[string]string      // 镜像标签
+	Platforms []string               // 镜像平台
+	Pushed    bool                   // 是否已在构建阶段推送
+	Pushes    []executor.PushedImage // 推送记录（含摘要）
+	Exports   []executor.ImageExport // 导出到文件的镜像
+	Error     string                 // 查询镜像失败的原因
+}
+
+// Digest 返回镜像摘要（同一次构建的所有标签指向同一个 manifest）
+func (s ImageSummary) Digest() string {
+	for _, push := range s.Pushes {
+		if push.Digest != "" {
+			return push.Digest
+		}
+	}
+	return ""
+}
+
+// Summary 运行摘要
+type Summary struct {
+	RunID        string
+	Images       []ImageSummary
+	ManifestPath string // images.json 路径，未写入时为空
+	ManifestErr  error  // 写入 images.json 失败的原因
+}
+
+// Summary 返回运行摘要（按任务完成顺序）
+func (p *Pipeline) Summary() Summary {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return Summary{
+		RunID:        p.runID,
+		Images:       append([]ImageSummary(nil), p.images...),
+		ManifestPath: p.manifestPath,
+		ManifestErr:  p.manifestErr,
+	}
+}
+
+// recordImage 记录构建结果，必要时查询镜像 ID 与标签
+func (p *Pipeline) recordImage(ctx context.Context, task *Task, builder executor.ImageBuilder, exec executor.Executor, handler executor.OutputHandler) {
+	summary := ImageSummary{
+		Task:   task.Name,
+		Images: builder.ImageNames(),
+		Pushed: builder.IsPushed(),
+	}
+
+	if exporter, ok := exec.(executor.ImageExporter); ok {
+		summary.Exports = exporter.ExportedImages()
+	}
+	if inspector, ok := exec.(executor.ImageInspector); ok {
+		inspectCtx, cancel := context.WithTimeout(ctx, inspectTimeout)
+		details, err := inspector.InspectImage(inspectCtx)
+		cancel()
+		if err != nil {
+			summary.Error = err.Error()
+			handler(fmt.Sprintf("⚠️  [WARN] 查询镜像信息失败: %v", err), false)
+		} else {
+			summary.ID = details.ID
+			summary.Labels = details.Labels
+			summary.Platforms = details.Platforms
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, imageName := range summary.Images {
+		p.builtImages = append(p.builtImages, imageName)
+		// 记录是否已在构建阶段推送
+		if summary.Pushed {
+			p.pushedImages[imageName] = true
+		}
+		// 记录导出文件（供 docker-push 从导出文件推送）
+		if len(summary.Exports) > 0 {
+			p.exports[imageName] = summary.Exports
+		}
+	}
+	p.images = append(p.images, summary)
+}
+
+// recordPushed 记录推送结果，并设置运行时变量 images.<名称>.digest / images.<名称>.ref
+func (p *Pipeline) recordPushed(task *Task, pushed []executor.PushedImage, handler executor.OutputHandler) {
+	var warnings []string
+	defer func() {
+		// 释放锁后再输出，避免阻塞其他任务
+		for _, w := range warnings {
+			handler(w, false)
+		}
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seen := make(map[string]bool) // 同名镜像推送到多个 Registry 时，变量指向第一个（主仓库）
+	for _, push := range pushed {
+		p.attachPush(task, push)
+		if push.Digest == "" {
+			continue
+		}
+
+		repo := repository(push.Image)
+		short := repo[strings.LastIndex(repo, "/")+1:]
+		key := "images." + short
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if prev, ok := p.runtimeVars[key+".repository"]; ok && prev != repo {
+			warnings = append(warnings, fmt.Sprintf("⚠️  [WARN] 镜像 %s 与 %s 同名，${%s.digest} 将指向 %s", prev, repo, key, repo))
+		}
+		p.runtimeVars[key+".repository"] = repo
+		p.runtimeVars[key+".digest"] = push.Digest
+		p.runtimeVars[key+".ref"] = repo + "@" + push.Digest
+	}
+}
+
+// attachPush 将推送记录关联到对应的构建结果，未在本次运行中构建的镜像单独记录（需持有写锁）
+func (p *Pipeline) attachPush(task *Task, push executor.PushedImage) {
+	for i := range p.images {
+		if slices.Contains(p.images[i].Images, push.Image) {
+			p.images[i].Pushes = append(p.images[i].Pushes, push)
+			return
+		}
+	}
+	// 同一仓库中摘要相同的镜像（如推送时追加的 latest 标签）归为一组
+	repo := repository(push.Image)
+	for i := range p.images {
+		sameRepo := slices.ContainsFunc(p.images[i].Images, func(name string) bool { return repository(name) == repo })
+		if sameRepo && push.Digest != "" && p.images[i].Digest() == push.Digest {
+			p.images[i].Images = append(p.images[i].Images, push.Image)
+			p.images[i].Pushes = append(p.images[i].Pushes, push)
+			return
+		}
+	}
+	p.images = append(p.images, ImageSummary{
+		Task:   task.Name,
+		Images: []string{push.Image},
+		Pushes: []executor.PushedImage{push},
+	})
+}
+
+// lookupRuntimeVar 查找运行时变量
+func (p *Pipeline) lookupRuntimeVar(name string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	v, ok := p.runtimeVars[name]
+	return v, ok
+}
+
+// manifestImage images.json 中的镜像条目
+type manifestImage struct {
+	Name      string   `json:"name"`
+	Tags      []string `json:"tags"`
+	Digest    string   `json:"digest,omitempty"`
+	Platforms []string `json:"platforms,omitempty"`
+	Registry  string   `json:"registry,omitempty"`
+	Exports   []string `json:"exports,omitempty"`
+	Task      string   `json:"task"`
+}
+
+// manifest images.json 结构
+type manifest struct {
+	RunID   string          `json:"run_id"`
+	Project string          `json:"project"`
+	Profile string          `json:"profile,omitempty"`
+	Created string          `json:"created"`
+	Images  []manifestImage `json:"images"`
+}
+
+// writeManifest 将本次运行的镜像写入 <配置目录>/.xbuilder/runs/<run id>/images.json
+func (p *Pipeline) writeManifest() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.images) == 0 {
+		return nil
+	}
+
+	m := manifest{
+		RunID:   p.runID,
+		Project: p.config.Project.Name,
+		Profile: p.config.Profile(),
+		Created: time.Now().UTC().Format(time.RFC3339),
+		Images:  make([]manifestImage, 0, len(p.images)),
+	}
+	for _, img := range p.images {
+		m.Images = append(m.Images, manifestEntries(img)...)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成镜像清单失败: %w", err)
+	}
+
+	dir := filepath.Join(p.config.Dir(), ".xbuilder", "runs", p.runID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建运行目录失败: %w", err)
+	}
+	path := filepath.Join(dir, "images.json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入镜像清单失败: %w", err)
+	}
+	p.manifestPath = path
+	return nil
+}
+
+// manifestEntries 按仓库拆分构建结果（tags 可能指向不同仓库）
+func manifestEntries(img ImageSummary) []manifestImage {
+	var entries []manifestImage
+	index := make(map[string]int)
+	for _, name := range img.Images {
+		repo := repository(name)
+		i, ok := index[repo]
+		if !ok {
+			i = len(entries)
+			index[repo] = i
+			entries = append(entries, manifestImage{Name: repo, Tags: []string{}, Platforms: img.Platforms, Task: img.Task})
+			for _, x := range img.Exports {
+				entries[i].Exports = append(entries[i].Exports, x.Path)
+			}
+		}
+		if tag := strings.TrimPrefix(name, repo); tag != "" {
+			entries[i].Tags = append(entries[i].Tags, strings.TrimPrefix(tag, ":"))
+		}
+		for _, push := range img.Pushes {
+			if push.Image == name && entries[i].Digest == "" {
+				entries[i].Digest = push.Digest
+				entries[i].Registry = push.Registry
+			}
+		}
+	}
+	return entries
+}
+
+// repository 返回镜像名称去除标签和摘要后的仓库部分
+func repository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
@@ -13,7 +13,7 @@ type Task struct {
 	ID         string
 	Name       string
 	Type       string
-	Config     config.TaskConfig
+	Settings   config.TaskSettings // 按任务类型解码后的配置
 	Status     types.TaskStatus
 	StartTime  time.Time
 	EndTime    time.Time
@@ -23,12 +23,12 @@ type Task struct {
 }
 
 // NewTask 创建新的任务
-func NewTask(stageIndex, taskIndex int, cfg config.Task) *Task {
+func NewTask(stageIndex, taskIndex int, name, taskType string, settings config.TaskSettings) *Task {
 	return &Task{
 		ID:         fmt.Sprintf("task-%d-%d", stageIndex, taskIndex),
-		Name:       cfg.Name,
-		Type:       cfg.Type,
-		Config:     cfg.Config,
+		Name:       name,
+		Type:       taskType,
+		Settings:   settings,
 		Status:     types.StatusPending,
 		StageIndex: stageIndex,
 		TaskIndex:  taskIndex,
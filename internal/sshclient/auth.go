@@ -0,0 +1,373 @@
+package sshclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// passphraseAttempts 交互输入密码短语的最多尝试次数
+const passphraseAttempts = 3
+
+// PromptFunc 交互式输入（如加密私钥的密码短语），secret 为 true 时输入不回显
+type PromptFunc func(ctx context.Context, question string, secret bool) (string, error)
+
+// signerCache 已解密的私钥（按路径），同一次运行中每个加密私钥只需输入一次密码短语
+var (
+	signerMu    sync.Mutex
+	signerCache = make(map[string]ssh.Signer)
+)
+
+// authenticator 按配置顺序组装一次连接使用的认证方式
+type authenticator struct {
+	ctx     context.Context
+	srv     config.Server
+	opts    Options
+	conn    net.Conn    // 握手中的连接，等待交互输入期间暂停握手超时
+	closers []io.Closer // ssh-agent 连接，握手结束后关闭
+	errs    []string    // 加载失败的认证方式，认证失败时附加到错误信息中
+}
+
+// newAuthenticator 创建认证器
+func newAuthenticator(ctx context.Context, srv config.Server, opts Options) *authenticator {
+	return &authenticator{ctx: ctx, srv: srv, opts: opts}
+}
+
+// methods 返回 ssh 认证方式
+// x/crypto/ssh 对同一类型的认证方式只尝试一次，因此 agent 与所有私钥合并为一个公钥认证，
+// 位置为其中第一个出现的位置；公钥按配置顺序逐个尝试
+func (a *authenticator) methods() []ssh.AuthMethod {
+	var methods []ssh.AuthMethod
+	publicKeys := false
+	for _, m := range a.srv.Auth.Chain() {
+		switch m.Type {
+		case config.AuthPassword:
+			methods = append(methods, ssh.Password(m.Password))
+		case config.AuthKeyboardInteractive:
+			methods = append(methods, ssh.KeyboardInteractive(a.keyboardInteractive(m)))
+		case config.AuthKey, config.AuthAgent:
+			if !publicKeys {
+				methods = append(methods, ssh.PublicKeysCallback(a.signers))
+				publicKeys = true
+			}
+		}
+	}
+	return methods
+}
+
+// signers 按配置顺序加载 agent 与私钥中的公钥；单个来源失败时记录原因并继续尝试其余方式
+func (a *authenticator) signers() ([]ssh.Signer, error) {
+	var signers []ssh.Signer
+	for _, m := range a.srv.Auth.Chain() {
+		var (
+			loaded []ssh.Signer
+			err    error
+			source string
+		)
+		switch m.Type {
+		case config.AuthAgent:
+			source = "ssh-agent"
+			loaded, err = a.agentSigners()
+		case config.AuthKey:
+			source = m.KeyPath
+			var signer ssh.Signer
+			signer, err = a.keySigner(m)
+			loaded = []ssh.Signer{signer}
+		default:
+			continue
+		}
+		if err != nil {
+			a.errs = append(a.errs, fmt.Sprintf("%s: %v", source, err))
+			a.log(fmt.Sprintf("⚠️  [WARN] 跳过认证方式 %s: %v", source, err))
+			continue
+		}
+		signers = append(signers, loaded...)
+	}
+	return signers, nil
+}
+
+// agentSigners 通过 SSH_AUTH_SOCK 连接 ssh-agent 并获取其中的密钥（含证书）
+func (a *authenticator) agentSigners() ([]ssh.Signer, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("SSH_AUTH_SOCK 未设置，ssh-agent 未运行")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(a.ctx, "unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("连接 ssh-agent 失败: %w", err)
+	}
+	a.closers = append(a.closers, conn)
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		return nil, fmt.Errorf("读取 ssh-agent 密钥失败: %w", err)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("ssh-agent 中没有密钥（可使用 ssh-add 添加）")
+	}
+	return signers, nil
+}
+
+// keySigner 加载私钥文件，存在证书时使用证书认证
+// 加密私钥未配置 passphrase 时延迟到服务器接受该公钥后再询问密码短语
+func (a *authenticator) keySigner(auth config.ServerAuth) (ssh.Signer, error) {
+	path := expandHomePath(auth.KeyPath)
+	signer, err := a.privateKey(path, auth.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	certPath := expandHomePath(auth.Certificate)
+	if certPath == "" {
+		// 与 OpenSSH 一致，自动使用私钥旁的 -cert.pub 证书
+		if _, err := os.Stat(path + "-cert.pub"); err != nil {
+			return signer, nil
+		}
+		certPath = path + "-cert.pub"
+	}
+	cert, err := readCertificate(certPath)
+	if err != nil {
+		return nil, err
+	}
+	if lazy, ok := signer.(*lazySigner); ok {
+		// 证书的公钥部分可直接读取，签名时再解密私钥
+		return &lazySigner{pub: cert, load: func() (ssh.Signer, error) {
+			s, err := lazy.get()
+			if err != nil {
+				return nil, err
+			}
+			return ssh.NewCertSigner(cert, s)
+		}}, nil
+	}
+	return ssh.NewCertSigner(cert, signer)
+}
+
+// privateKey 读取并解析私钥
+func (a *authenticator) privateKey(path, passphrase string) (ssh.Signer, error) {
+	signerMu.Lock()
+	cached, ok := signerCache[path]
+	signerMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case err == nil:
+		return signer, nil
+	case !errors.As(err, &missing):
+		return nil, fmt.Errorf("解析密钥失败: %w", err)
+	case passphrase != "":
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("使用 passphrase 解密私钥失败: %w", err)
+		}
+		cacheSigner(path, signer)
+		return signer, nil
+	}
+
+	decrypt := func() (ssh.Signer, error) { return a.decryptKey(path, data) }
+	pub := missing.PublicKey
+	if pub == nil {
+		// PEM 格式的加密私钥不含公钥，尝试读取 .pub 文件
+		if pubData, err := os.ReadFile(path + ".pub"); err == nil {
+			pub, _, _, _, _ = ssh.ParseAuthorizedKey(pubData)
+		}
+	}
+	if pub == nil {
+		return decrypt()
+	}
+	return &lazySigner{pub: pub, load: decrypt}, nil
+}
+
+// decryptKey 询问密码短语并解密私钥
+func (a *authenticator) decryptKey(path string, data []byte) (ssh.Signer, error) {
+	signerMu.Lock()
+	defer signerMu.Unlock()
+	if cached, ok := signerCache[path]; ok {
+		return cached, nil
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= passphraseAttempts; attempt++ {
+		question := fmt.Sprintf("输入私钥 %s 的密码短语", path)
+		if attempt > 1 {
+			question = fmt.Sprintf("密码短语错误，请重新输入私钥 %s 的密码短语 (%d/%d)", path, attempt, passphraseAttempts)
+		}
+		passphrase, err := a.prompt(question, true)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+		if err == nil {
+			signerCache[path] = signer
+			return signer, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("解密私钥 %s 失败: %w", path, lastErr)
+}
+
+// keyboardInteractive 回答服务器的提问：不回显的问题优先使用配置的 password，否则在 TUI 中输入
+func (a *authenticator) keyboardInteractive(auth config.ServerAuth) ssh.KeyboardInteractiveChallenge {
+	return func(name, instruction string, questions []string, echos []bool) ([]string, error) {
+		answers := make([]string, len(questions))
+		for i, q := range questions {
+			if !echos[i] && auth.Password != "" {
+				answers[i] = auth.Password
+				continue
+			}
+			question := strings.TrimSpace(q)
+			if instruction = strings.TrimSpace(instruction); instruction != "" {
+				question = instruction + " " + question
+			}
+			answer, err := a.prompt(fmt.Sprintf("%s@%s %s", a.srv.Username, a.srv.Host, question), !echos[i])
+			if err != nil {
+				return nil, err
+			}
+			answers[i] = answer
+		}
+		return answers, nil
+	}
+}
+
+// prompt 交互式输入，无法交互时返回错误
+func (a *authenticator) prompt(question string, secret bool) (string, error) {
+	if a.opts.Prompt == nil {
+		return "", fmt.Errorf("需要输入「%s」，但当前无法交互（可在配置中设置 passphrase/password）", question)
+	}
+	if a.conn != nil {
+		a.conn.SetDeadline(time.Time{})
+		defer a.conn.SetDeadline(time.Now().Add(dialTimeout))
+	}
+	return a.opts.Prompt(a.ctx, question, secret)
+}
+
+// log 输出日志
+func (a *authenticator) log(msg string) {
+	if a.opts.Log != nil {
+		a.opts.Log(msg)
+	}
+}
+
+// close 关闭认证过程中打开的连接
+func (a *authenticator) close() {
+	for _, c := range a.closers {
+		c.Close()
+	}
+	a.closers = nil
+}
+
+// lazySigner 延迟解密的签名器：公钥可直接读取，服务器接受该公钥、需要签名时才解密私钥
+type lazySigner struct {
+	pub  ssh.PublicKey
+	load func() (ssh.Signer, error)
+
+	once   sync.Once
+	signer ssh.Signer
+	err    error
+}
+
+// get 解密并返回实际的签名器（只解密一次）
+func (s *lazySigner) get() (ssh.Signer, error) {
+	s.once.Do(func() { s.signer, s.err = s.load() })
+	return s.signer, s.err
+}
+
+func (s *lazySigner) PublicKey() ssh.PublicKey {
+	return s.pub
+}
+
+func (s *lazySigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+func (s *lazySigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	signer, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	if as, ok := signer.(ssh.AlgorithmSigner); ok {
+		return as.SignWithAlgorithm(rand, data, algorithm)
+	}
+	return signer.Sign(rand, data)
+}
+
+// readCertificate 读取 OpenSSH 证书
+func readCertificate(path string) (*ssh.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取证书失败: %w", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析证书失败 [%s]: %w", path, err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s 不是 OpenSSH 证书", path)
+	}
+	return cert, nil
+}
+
+// cacheSigner 缓存已解密的私钥
+func cacheSigner(path string, signer ssh.Signer) {
+	signerMu.Lock()
+	defer signerMu.Unlock()
+	signerCache[path] = signer
+}
+
+// CheckRoute 检查服务器及其跳板机的认证配置（见 CheckAuth）
+func CheckRoute(srv config.Server, jumps []config.Server) error {
+	if err := CheckAuth(srv.Auth); err != nil {
+		return fmt.Errorf("创建 SSH 认证失败: %w", err)
+	}
+	for _, hop := range jumps {
+		if err := CheckAuth(hop.Auth); err != nil {
+			return fmt.Errorf("创建跳板机 %s 的 SSH 认证失败: %w", Address(hop), err)
+		}
+	}
+	return nil
+}
+
+// CheckAuth 检查认证配置能否加载（如密钥文件不存在、格式错误），不询问密码短语、不连接 ssh-agent
+func CheckAuth(auth config.ServerAuth) error {
+	for _, m := range auth.Chain() {
+		switch m.Type {
+		case config.AuthPassword, config.AuthAgent, config.AuthKeyboardInteractive:
+		case config.AuthKey:
+			data, err := os.ReadFile(expandHomePath(m.KeyPath))
+			if err != nil {
+				return fmt.Errorf("读取密钥文件失败: %w", err)
+			}
+			var missing *ssh.PassphraseMissingError
+			if _, err := ssh.ParseRawPrivateKey(data); err != nil && !errors.As(err, &missing) {
+				return fmt.Errorf("解析密钥失败 [%s]: %w", m.KeyPath, err)
+			}
+			if m.Certificate != "" {
+				if _, err := readCertificate(expandHomePath(m.Certificate)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("不支持的认证类型: %s", m.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package sshclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// knownHostsMu 串行化 known_hosts 的读取与追加（并行任务可能同时首次连接同一主机）
+var knownHostsMu sync.Mutex
+
+// HostKeyMismatchError 主机公钥与记录不一致
+type HostKeyMismatchError struct {
+	Host     string
+	Expected []string // 期望的指纹（含来源，如 known_hosts:12）
+	Actual   string   // 服务器提供的公钥指纹
+	Hint     string
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	msg := fmt.Sprintf("主机密钥不匹配 [%s]，连接可能被中间人劫持！\n  期望: %s\n  实际: %s",
+		e.Host, strings.Join(e.Expected, "\n        "), e.Actual)
+	if e.Hint != "" {
+		msg += "\n  " + e.Hint
+	}
+	return msg
+}
+
+// UnknownHostError strict 策略下主机公钥没有记录
+type UnknownHostError struct {
+	Host        string
+	KnownHosts  string
+	Fingerprint string
+	KeyType     string   // 服务器提供的公钥类型
+	KnownTypes  []string // known_hosts 中已记录的其他类型（为空表示主机没有任何记录）
+}
+
+func (e *UnknownHostError) Error() string {
+	if len(e.KnownTypes) > 0 {
+		return fmt.Sprintf("主机 %s 提供的 %s 公钥在 %s 中没有记录（已记录类型: %s，公钥指纹 %s）\n  确认指纹无误后可执行 ssh-keyscan -t %s -p %s %s >> %s",
+			e.Host, e.KeyType, e.KnownHosts, strings.Join(e.KnownTypes, ", "), e.Fingerprint, keyscanType(e.KeyType), portOf(e.Host), hostOf(e.Host), e.KnownHosts)
+	}
+	return fmt.Sprintf("未知的主机 %s（%s 中没有记录，公钥指纹 %s）\n  确认指纹无误后可执行 ssh-keyscan -p %s %s >> %s，或设置 host_key_policy: tofu / host_key: %s",
+		e.Host, e.KnownHosts, e.Fingerprint, portOf(e.Host), hostOf(e.Host), e.KnownHosts, e.Fingerprint)
+}
+
+// HostKeyCallback 按服务器的 host_key_policy 创建主机密钥校验回调
+// host_key 优先；否则使用 known_hosts（tofu 策略下首次连接时记录公钥）
+func HostKeyCallback(srv config.Server, log func(string)) (ssh.HostKeyCallback, error) {
+	if srv.HostKey != "" {
+		return pinnedHostKey(srv.HostKey)
+	}
+
+	policy := srv.Policy()
+	if policy == config.HostKeyInsecure {
+		return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+			log(fmt.Sprintf("⚠️  [WARN] 未校验主机密钥 (host_key_policy: insecure): %s %s", hostname, ssh.FingerprintSHA256(key)))
+			return nil
+		}, nil
+	}
+
+	path := knownHostsPath(srv.KnownHosts)
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		knownHostsMu.Lock()
+		defer knownHostsMu.Unlock()
+
+		err := checkKnownHosts(path, hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err != nil && !errors.As(err, &keyErr) {
+			return err
+		}
+		// 只有记录了同类型的公钥时才是密钥不匹配；只记录了其他类型时视为该类型没有记录
+		knownTypes := keyTypes(keyErr)
+		switch {
+		case err == nil:
+			return nil
+		case slices.Contains(knownTypes, key.Type()):
+			return mismatchError(hostname, path, keyErr.Want, key)
+		case policy == config.HostKeyTOFU:
+			if err := appendKnownHost(path, hostname, key); err != nil {
+				return fmt.Errorf("记录主机密钥失败: %w", err)
+			}
+			if len(knownTypes) > 0 {
+				log(fmt.Sprintf("🔑 %s 提供了未记录的 %s 公钥，已记录主机密钥 %s 到 %s", hostname, key.Type(), ssh.FingerprintSHA256(key), path))
+			} else {
+				log(fmt.Sprintf("🔑 首次连接 %s，已记录主机密钥 %s 到 %s", hostname, ssh.FingerprintSHA256(key), path))
+			}
+			return nil
+		default:
+			return &UnknownHostError{Host: hostname, KnownHosts: path, Fingerprint: ssh.FingerprintSHA256(key), KeyType: key.Type(), KnownTypes: knownTypes}
+		}
+	}, nil
+}
+
+// HostKeyAlgorithms 返回握手时协商的主机密钥算法：已记录（host_key 或 known_hosts）的密钥类型优先，其余算法在后
+// 与 OpenSSH 一致，避免服务器优先提供未记录类型的公钥；没有记录时返回 nil（使用默认顺序）
+func HostKeyAlgorithms(srv config.Server) []string {
+	var types []string
+	switch {
+	case srv.HostKey != "":
+		// SHA256 指纹无法得知密钥类型
+		if pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(srv.HostKey)); err == nil {
+			types = []string{pub.Type()}
+		}
+	case srv.Policy() != config.HostKeyInsecure:
+		knownHostsMu.Lock()
+		types = knownKeyTypes(knownHostsPath(srv.KnownHosts), Address(srv))
+		knownHostsMu.Unlock()
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	all := append(ssh.SupportedAlgorithms().HostKeys, ssh.InsecureAlgorithms().HostKeys...)
+	var algos []string
+	for _, t := range types {
+		for _, algo := range keyTypeAlgorithms(t) {
+			if slices.Contains(all, algo) && !slices.Contains(algos, algo) {
+				algos = append(algos, algo)
+			}
+		}
+	}
+	if len(algos) == 0 {
+		return nil
+	}
+	for _, algo := range all {
+		if !slices.Contains(algos, algo) {
+			algos = append(algos, algo)
+		}
+	}
+	return algos
+}
+
+// keyTypeAlgorithms 返回公钥类型对应的签名算法（RSA 优先使用 SHA-2）
+func keyTypeAlgorithms(keyType string) []string {
+	switch keyType {
+	case ssh.KeyAlgoRSA:
+		return []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+	case ssh.CertAlgoRSAv01:
+		return []string{ssh.CertAlgoRSASHA512v01, ssh.CertAlgoRSASHA256v01, ssh.CertAlgoRSAv01}
+	default:
+		return []string{keyType}
+	}
+}
+
+// knownKeyTypes 返回 known_hosts 中主机已记录的公钥类型（文件不存在或无法读取时为空）
+func knownKeyTypes(path, hostname string) []string {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil
+	}
+	// 用不可能被记录的探测公钥校验，KeyError.Want 中即为该主机的全部记录
+	var keyErr *knownhosts.KeyError
+	if err := callback(hostname, &net.TCPAddr{IP: net.IPv4zero}, probeKey{}); !errors.As(err, &keyErr) {
+		return nil
+	}
+	return keyTypes(keyErr)
+}
+
+// keyTypes 返回 KeyError 中已记录公钥的类型（已去重）
+func keyTypes(keyErr *knownhosts.KeyError) []string {
+	if keyErr == nil {
+		return nil
+	}
+	var types []string
+	for _, k := range keyErr.Want {
+		if t := k.Key.Type(); !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// keyscanType 返回 ssh-keyscan -t 使用的密钥类型名称
+func keyscanType(keyType string) string {
+	switch {
+	case keyType == ssh.KeyAlgoRSA:
+		return "rsa"
+	case keyType == ssh.KeyAlgoED25519:
+		return "ed25519"
+	case strings.HasPrefix(keyType, "ecdsa-"):
+		return "ecdsa"
+	default:
+		return keyType
+	}
+}
+
+// probeKey 查询 known_hosts 记录用的探测公钥，与任何记录都不相同
+type probeKey struct{}
+
+func (probeKey) Type() string    { return "xbuilder-probe" }
+func (probeKey) Marshal() []byte { return []byte("xbuilder-probe") }
+func (probeKey) Verify([]byte, *ssh.Signature) error {
+	return errors.New("探测公钥不能用于校验签名")
+}
+
+// pinnedHostKey 校验服务器公钥与 host_key 一致
+func pinnedHostKey(hostKey string) (ssh.HostKeyCallback, error) {
+	want := hostKey
+	if !strings.HasPrefix(hostKey, "SHA256:") {
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+		if err != nil {
+			return nil, fmt.Errorf("解析 host_key 失败: %w", err)
+		}
+		want = ssh.FingerprintSHA256(pub)
+	}
+	want = strings.TrimSuffix(want, "=")
+
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		if actual := ssh.FingerprintSHA256(key); actual != want {
+			return &HostKeyMismatchError{
+				Host:     hostname,
+				Expected: []string{want + " (host_key)"},
+				Actual:   fmt.Sprintf("%s (%s)", actual, key.Type()),
+				Hint:     "若服务器确实更换了密钥，请更新配置中的 host_key",
+			}
+		}
+		return nil
+	}, nil
+}
+
+// checkKnownHosts 在 known_hosts 中校验公钥，文件不存在时视为没有记录
+func checkKnownHosts(path, hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &knownhosts.KeyError{}
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return fmt.Errorf("读取 known_hosts 失败: %w", err)
+	}
+	return callback(hostname, remote, key)
+}
+
+// appendKnownHost 将主机公钥追加到 known_hosts
+func appendKnownHost(path, hostname string, key ssh.PublicKey) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// 保证新记录从新行开始
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		if data, err := os.ReadFile(path); err == nil && !bytes.HasSuffix(data, []byte("\n")) {
+			if _, err := f.WriteString("\n"); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n")
+	return err
+}
+
+// mismatchError 生成 known_hosts 记录不一致的错误
+func mismatchError(hostname, path string, want []knownhosts.KnownKey, key ssh.PublicKey) error {
+	expected := make([]string, 0, len(want))
+	for _, k := range want {
+		expected = append(expected, fmt.Sprintf("%s (%s, %s:%d)", ssh.FingerprintSHA256(k.Key), k.Key.Type(), k.Filename, k.Line))
+	}
+	return &HostKeyMismatchError{
+		Host:     hostname,
+		Expected: expected,
+		Actual:   fmt.Sprintf("%s (%s)", ssh.FingerprintSHA256(key), key.Type()),
+		Hint:     fmt.Sprintf("若服务器确实更换了密钥，请执行 ssh-keygen -R %s -f %s 后重新连接", knownhosts.Normalize(hostname), path),
+	}
+}
+
+// knownHostsPath 返回 known_hosts 文件路径
+func knownHostsPath(path string) string {
+	if path == "" {
+		return expandHomePath("~/.ssh/known_hosts")
+	}
+	return expandHomePath(path)
+}
+
+// hostOf 返回 host:port 中的主机部分
+func hostOf(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport
+	}
+	return host
+}
+
+// portOf 返回 host:port 中的端口部分
+func portOf(hostport string) string {
+	_, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "22"
+	}
+	return port
+}
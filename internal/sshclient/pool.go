@@ -0,0 +1,265 @@
+package sshclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// keepaliveInterval 连接池中的连接发送 keepalive 的间隔
+	keepaliveInterval = 30 * time.Second
+	// keepaliveTimeout 等待 keepalive 响应的超时时间，超时视为连接已断开
+	keepaliveTimeout = 15 * time.Second
+	// reuseCheckTimeout 复用连接前检查连接的超时时间，超时则改用新连接（连接是否断开由后台 keepalive 判断）
+	reuseCheckTimeout = 3 * time.Second
+)
+
+// errPoolClosed 连接池已关闭
+var errPoolClosed = errors.New("SSH 连接池已关闭")
+
+// owners 连接池建立的连接 → 所属的服务器连接，NewSession 据此为 session 数已满的连接找到额外连接
+var owners sync.Map
+
+// Pool SSH 连接池：按服务器名称复用连接（含跳板机），供一次流水线运行内的所有任务共用
+// 连接建立后定期发送 keepalive@openssh.com，避免长时间执行的命令或任务间隔期间被服务器、防火墙断开；
+// 连接断开后下次获取时自动重新连接。并行任务打开的 session 超过服务器 MaxSessions 时，
+// 由 NewSession 在同一服务器的额外连接上打开
+type Pool struct {
+	mu     sync.Mutex
+	conns  map[string]*poolConn
+	closed bool
+}
+
+// poolConn 连接池中的一个服务器连接
+type poolConn struct {
+	mu     sync.Mutex // 建立连接期间持有，同一服务器的并发获取等待同一次连接
+	client *ssh.Client
+	done   chan struct{} // 连接断开后关闭
+	stop   chan struct{} // 连接池关闭时关闭，结束 keepalive
+	// retired 复用检查无响应后不再分配、但可能仍被其他任务使用的连接
+	// 确实断开时由各自的 keepalive 关闭，连接池关闭时一并关闭
+	retired []*ssh.Client
+	// extra 主连接的 session 数达到服务器上限时建立的额外连接，断开后移除
+	extra  []*ssh.Client
+	srv    config.Server
+	opts   Options // 建立额外连接使用的选项（不输出日志，日志属于当初获取连接的任务）
+	closed bool
+}
+
+// NewPool 创建 SSH 连接池
+func NewPool() *Pool {
+	return &Pool{conns: make(map[string]*poolConn)}
+}
+
+// Get 获取服务器的连接：已有可用的连接时直接复用，否则（首次或连接已断开）建立新连接
+// name 为服务器名称，同名服务器共用一个连接；返回的连接由连接池管理，调用方不要关闭
+func (p *Pool) Get(ctx context.Context, name string, srv config.Server, opts Options) (*ssh.Client, error) {
+	if opts.Log == nil {
+		opts.Log = func(string) {}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errPoolClosed
+	}
+	pc, ok := p.conns[name]
+	if !ok {
+		pc = &poolConn{stop: make(chan struct{})}
+		p.conns[name] = pc
+	}
+	p.mu.Unlock()
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.srv, pc.opts = srv, opts
+	pc.opts.Log = func(string) {}
+
+	if pc.client != nil {
+		select {
+		case <-pc.done:
+			pc.client.Close()
+			opts.Log("🔄 SSH 连接已断开，重新连接")
+		default:
+			if ping(pc.client, reuseCheckTimeout) == nil {
+				opts.Log("♻️  复用已有 SSH 连接")
+				return pc.client, nil
+			}
+			// 连接可能只是繁忙（如其他任务正在传输大量数据），不关闭，避免中断正在使用它的任务
+			pc.retired = append(pc.retired, pc.client)
+			opts.Log(fmt.Sprintf("🔄 SSH 连接 %s 内无响应，建立新连接", reuseCheckTimeout))
+		}
+		pc.client = nil
+	}
+
+	client, err := Dial(ctx, srv, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 连接池在连接期间被关闭时不再保留新连接
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		client.Close()
+		return nil, errPoolClosed
+	}
+
+	pc.client = client
+	pc.done = pc.track(client)
+	return client, nil
+}
+
+// track 登记连接池建立的连接并启动 keepalive，返回连接断开后关闭的 channel，调用方须持有 pc.mu
+func (pc *poolConn) track(client *ssh.Client) chan struct{} {
+	owners.Store(client, pc)
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		owners.Delete(client)
+		close(done)
+	}()
+	go keepalive(client, done, pc.stop)
+	return done
+}
+
+// extraSession 主连接 full 的 session 数已达服务器上限时，在额外连接上打开 session，没有可用的额外连接时建立新连接
+func (pc *poolConn) extraSession(ctx context.Context, full *ssh.Client) (*ssh.Session, error) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	for _, c := range append([]*ssh.Client{pc.client}, pc.extra...) {
+		if c == nil || c == full {
+			continue
+		}
+		if session, err := c.NewSession(); err == nil {
+			return session, nil
+		}
+	}
+	if pc.closed {
+		return nil, errPoolClosed
+	}
+
+	client, err := Dial(ctx, pc.srv, pc.opts)
+	if err != nil {
+		return nil, fmt.Errorf("session 数已达服务器上限（MaxSessions），建立额外连接失败: %w", err)
+	}
+	pc.extra = append(pc.extra, client)
+	done := pc.track(client)
+	go func() {
+		<-done
+		pc.mu.Lock()
+		defer pc.mu.Unlock()
+		pc.extra = slices.DeleteFunc(pc.extra, func(c *ssh.Client) bool { return c == client })
+	}()
+	return client.NewSession()
+}
+
+// NewSession 在 client 上打开 session
+// 连接池中的连接因服务器 MaxSessions（OpenSSH 默认 10）被拒绝时，改在同一服务器的额外连接上打开，而不是直接失败
+func NewSession(ctx context.Context, client *ssh.Client) (*ssh.Session, error) {
+	session, err := client.NewSession()
+	if err == nil || !sessionLimited(err) {
+		return session, err
+	}
+	pc, ok := owners.Load(client)
+	if !ok {
+		return nil, err
+	}
+	return pc.(*poolConn).extraSession(ctx, client)
+}
+
+// sessionLimited 判断打开 session 是否因服务器的 session 数限制被拒绝（administratively prohibited）
+func sessionLimited(err error) bool {
+	var openErr *ssh.OpenChannelError
+	return errors.As(err, &openErr) && openErr.Reason == ssh.Prohibited
+}
+
+// Close 关闭连接池中的所有连接
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	conns := p.conns
+	p.conns = nil
+	p.mu.Unlock()
+
+	for _, pc := range conns {
+		close(pc.stop)
+		pc.mu.Lock()
+		if pc.client != nil {
+			pc.client.Close()
+			pc.client = nil
+		}
+		for _, c := range append(pc.retired, pc.extra...) {
+			c.Close()
+		}
+		pc.retired, pc.extra = nil, nil
+		pc.closed = true
+		pc.mu.Unlock()
+	}
+	return nil
+}
+
+// keepalive 定期发送 keepalive，无响应时关闭连接（下次获取时重新连接）
+func keepalive(client *ssh.Client, done, stop <-chan struct{}) {
+	ticker := time.NewTicker(keepaliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := ping(client, keepaliveTimeout); err != nil {
+				client.Close()
+				return
+			}
+		}
+	}
+}
+
+// ping 发送 keepalive@openssh.com 请求并在 timeout 内等待响应（服务器回复不支持也说明连接可用）
+func ping(client *ssh.Client, timeout time.Duration) error {
+	errc := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("keepalive 超时（%s 无响应）", timeout)
+	}
+}
+
+// Connect 连接服务器：pool 不为空时从连接池获取（复用已有连接），否则单独建立连接
+// 使用完毕后调用返回的 release：单独建立的连接随之关闭，连接池中的连接保留给后续任务
+func Connect(ctx context.Context, pool *Pool, name string, srv config.Server, opts Options) (*ssh.Client, func(), error) {
+	if pool != nil {
+		client, err := pool.Get(ctx, name, srv, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, func() {}, nil
+	}
+	client, err := Dial(ctx, srv, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, func() { client.Close() }, nil
+}
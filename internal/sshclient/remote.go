@@ -0,0 +1,40 @@
+package sshclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Quote 将字符串转义为 POSIX shell 单引号字符串
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Output 在远程执行命令并返回标准输出，失败时错误信息包含标准错误输出；ctx 取消时关闭 session
+func Output(ctx context.Context, client *ssh.Client, command string) (string, error) {
+	session, err := NewSession(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("创建 SSH session 失败: %w", err)
+	}
+	defer session.Close()
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if err := session.Run(command); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
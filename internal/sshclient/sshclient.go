@@ -0,0 +1,146 @@
+// Package sshclient 建立 SSH 连接（认证、主机密钥校验），供 ssh 等远程任务共用
+package sshclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xiaolfeng/builder-cli/internal/config"
+	"golang.org/x/crypto/ssh"
+)
+
+// dialTimeout 建立连接（含握手）的超时时间
+const dialTimeout = 30 * time.Second
+
+// Address 返回服务器的 host:port 地址（端口默认 22）
+func Address(srv config.Server) string {
+	port := srv.Port
+	if port == 0 {
+		port = 22
+	}
+	return net.JoinHostPort(srv.Host, strconv.Itoa(port))
+}
+
+// Options 连接选项
+type Options struct {
+	Log    func(string)    // 输出日志（如 tofu 记录主机密钥、跳过的认证方式）
+	Prompt PromptFunc      // 交互式输入，为 nil 时需要输入的认证方式直接失败
+	Jumps  []config.Server // 依次经过的跳板机
+}
+
+// Dial 连接服务器（设置 Jumps 时依次经跳板机转发，每一跳单独校验主机密钥），ctx 取消时中止握手
+// 返回的客户端关闭后，跳板机的连接随之关闭
+func Dial(ctx context.Context, srv config.Server, opts Options) (*ssh.Client, error) {
+	if opts.Log == nil {
+		opts.Log = func(string) {}
+	}
+
+	var jumps []*ssh.Client
+	closeJumps := func() {
+		for i := len(jumps) - 1; i >= 0; i-- {
+			jumps[i].Close()
+		}
+	}
+	var via *ssh.Client
+	for i, hop := range opts.Jumps {
+		opts.Log(fmt.Sprintf("🔀 [%d/%d] 连接跳板机: %s@%s", i+1, len(opts.Jumps), hop.Username, Address(hop)))
+		c, err := dialVia(ctx, via, hop, opts)
+		if err != nil {
+			closeJumps()
+			return nil, fmt.Errorf("连接跳板机 %s 失败: %w", Address(hop), err)
+		}
+		jumps = append(jumps, c)
+		via = c
+	}
+	if via != nil {
+		opts.Log(fmt.Sprintf("🔀 经跳板机连接: %s@%s", srv.Username, Address(srv)))
+	}
+
+	client, err := dialVia(ctx, via, srv, opts)
+	if err != nil {
+		closeJumps()
+		return nil, err
+	}
+	if len(jumps) > 0 {
+		go func() {
+			client.Wait()
+			closeJumps()
+		}()
+	}
+	return client, nil
+}
+
+// dialVia 建立到服务器的连接并完成握手，via 不为空时经该跳板机转发
+func dialVia(ctx context.Context, via *ssh.Client, srv config.Server, opts Options) (*ssh.Client, error) {
+	hostKeyCallback, err := HostKeyCallback(srv, opts.Log)
+	if err != nil {
+		return nil, err
+	}
+	auth := newAuthenticator(ctx, srv, opts)
+	defer auth.close()
+	cfg := &ssh.ClientConfig{
+		User:            srv.Username,
+		Auth:            auth.methods(),
+		HostKeyCallback: hostKeyCallback,
+		// 优先协商已记录类型的主机密钥，避免服务器提供其他类型的公钥被误判为不匹配
+		HostKeyAlgorithms: HostKeyAlgorithms(srv),
+		Timeout:           dialTimeout,
+	}
+
+	addr := Address(srv)
+	var conn net.Conn
+	if via != nil {
+		if conn, err = via.DialContext(ctx, "tcp", addr); err != nil {
+			return nil, fmt.Errorf("经跳板机转发失败: %w", err)
+		}
+	} else {
+		dialer := net.Dialer{Timeout: dialTimeout}
+		if conn, err = dialer.DialContext(ctx, "tcp", addr); err != nil {
+			return nil, fmt.Errorf("SSH 连接失败: %w", err)
+		}
+	}
+
+	// 握手期间 ctx 取消时关闭连接（经跳板机转发的连接不支持 deadline，依赖 ctx 取消）
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	auth.conn = conn
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
+	if err != nil {
+		conn.Close()
+		// 主机密钥校验失败时直接返回详细说明
+		var mismatch *HostKeyMismatchError
+		var unknown *UnknownHostError
+		switch {
+		case errors.As(err, &mismatch):
+			return nil, mismatch
+		case errors.As(err, &unknown):
+			return nil, unknown
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		}
+		if len(auth.errs) > 0 {
+			return nil, fmt.Errorf("SSH 连接失败: %w（%s）", err, strings.Join(auth.errs, "; "))
+		}
+		return nil, fmt.Errorf("SSH 连接失败: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// expandHomePath 展开 ~ 为 home 目录
+func expandHomePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
@@ -0,0 +1,349 @@
+// Package sshconfig 解析 OpenSSH 客户端配置（~/.ssh/config），按 Host 别名查询连接参数
+//
+// 支持 Host 模式（*、?、! 取反）、Include 以及常用的连接选项；Match 块会被跳过。
+// 与 OpenSSH 一致，同一选项以第一次出现的值为准（IdentityFile、CertificateFile 可累加）。
+package sshconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxIncludeDepth Include 的最大嵌套层数
+const maxIncludeDepth = 16
+
+// Host 按别名解析后的连接参数，未设置的字段为零值
+type Host struct {
+	Alias                 string
+	HostName              string // 未设置时为别名本身
+	Port                  int
+	User                  string
+	IdentityFiles         []string // 已展开 ~ 与 %d/%h/%r/%u
+	CertificateFiles      []string
+	IdentitiesOnly        bool
+	IdentityAgent         string // none 表示不使用 ssh-agent
+	ProxyJump             string // 如 bastion 或 user@bastion:2222,inner
+	ProxyCommand          string
+	UserKnownHostsFile    string
+	StrictHostKeyChecking string // yes | no | accept-new | ask
+	Matched               bool   // 是否有 Host * 以外的块匹配到该别名
+}
+
+// Config 解析后的 ssh 配置文件
+type Config struct {
+	Path   string
+	blocks []*block
+}
+
+// block Host 块（文件开头、第一个 Host 之前的选项属于匹配所有主机的块）
+type block struct {
+	patterns []string
+	match    bool // Match 块，跳过
+	options  []option
+}
+
+// option 单个配置项
+type option struct {
+	key   string // 小写
+	value string
+	file  string
+	line  int
+}
+
+// Load 读取并解析 ssh 配置文件
+func Load(path string) (*Config, error) {
+	cfg := &Config{Path: path}
+	if err := cfg.parseFile(path, &block{patterns: []string{"*"}}, 0); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// DefaultPath 返回默认的 ssh 配置文件路径（~/.ssh/config）
+func DefaultPath() string {
+	return expandHome("~/.ssh/config")
+}
+
+// parseFile 解析文件，current 为 Include 所在的块
+func (c *Config) parseFile(path string, current *block, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("%s: Include 嵌套过深", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.parse(f, path, current, depth)
+}
+
+// parse 逐行解析配置
+func (c *Config) parse(r io.Reader, path string, current *block, depth int) error {
+	c.blocks = append(c.blocks, current)
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		key, args, err := splitLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		if key == "" {
+			continue
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("%s:%d: %s 缺少参数", path, lineNo, key)
+		}
+
+		switch key {
+		case "host":
+			current = &block{patterns: args}
+			c.blocks = append(c.blocks, current)
+		case "match":
+			current = &block{match: true}
+			c.blocks = append(c.blocks, current)
+		case "include":
+			if current.match {
+				continue
+			}
+			parent := current
+			for _, pattern := range args {
+				matches, err := filepath.Glob(includePath(pattern))
+				if err != nil {
+					return fmt.Errorf("%s:%d: Include %s: %w", path, lineNo, pattern, err)
+				}
+				for _, m := range matches {
+					// 被包含文件开头的选项仍属于当前块
+					if err := c.parseFile(m, &block{patterns: parent.patterns}, depth+1); err != nil {
+						return err
+					}
+				}
+			}
+			// Include 结束后，后续选项仍属于原来的块
+			current = &block{patterns: parent.patterns, match: parent.match}
+			c.blocks = append(c.blocks, current)
+		default:
+			current.options = append(current.options, option{
+				key:   key,
+				value: strings.Join(args, " "),
+				file:  path,
+				line:  lineNo,
+			})
+		}
+	}
+	return scanner.Err()
+}
+
+// Get 查询别名的连接参数
+func (c *Config) Get(alias string) (*Host, error) {
+	h := &Host{Alias: alias}
+	seen := make(map[string]bool)
+	for _, b := range c.blocks {
+		if b.match {
+			continue
+		}
+		matched, exact := matchPatterns(b.patterns, alias)
+		if !matched {
+			continue
+		}
+		if exact {
+			h.Matched = true
+		}
+		for _, opt := range b.options {
+			if err := h.apply(opt, seen); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", opt.file, opt.line, err)
+			}
+		}
+	}
+
+	if h.HostName == "" {
+		h.HostName = alias
+	}
+	h.HostName = expandTokens(h.HostName, alias, h.User)
+	for i, f := range h.IdentityFiles {
+		h.IdentityFiles[i] = expandPath(f, h.HostName, h.User)
+	}
+	for i, f := range h.CertificateFiles {
+		h.CertificateFiles[i] = expandPath(f, h.HostName, h.User)
+	}
+	if h.UserKnownHostsFile != "" {
+		h.UserKnownHostsFile = expandPath(h.UserKnownHostsFile, h.HostName, h.User)
+	}
+	return h, nil
+}
+
+// apply 应用单个选项，已设置的选项保留第一次出现的值
+func (h *Host) apply(opt option, seen map[string]bool) error {
+	switch opt.key {
+	case "identityfile":
+		h.IdentityFiles = append(h.IdentityFiles, opt.value)
+		return nil
+	case "certificatefile":
+		h.CertificateFiles = append(h.CertificateFiles, opt.value)
+		return nil
+	}
+
+	if seen[opt.key] {
+		return nil
+	}
+	seen[opt.key] = true
+
+	switch opt.key {
+	case "hostname":
+		h.HostName = opt.value
+	case "port":
+		port, err := strconv.Atoi(opt.value)
+		if err != nil || port <= 0 || port > 65535 {
+			return fmt.Errorf("无效的 Port: %s", opt.value)
+		}
+		h.Port = port
+	case "user":
+		h.User = opt.value
+	case "identitiesonly":
+		h.IdentitiesOnly = strings.EqualFold(opt.value, "yes")
+	case "identityagent":
+		h.IdentityAgent = opt.value
+	case "proxyjump":
+		h.ProxyJump = opt.value
+	case "proxycommand":
+		h.ProxyCommand = opt.value
+	case "userknownhostsfile":
+		// 可列出多个文件，使用第一个
+		h.UserKnownHostsFile = strings.Fields(opt.value)[0]
+	case "stricthostkeychecking":
+		h.StrictHostKeyChecking = strings.ToLower(opt.value)
+	}
+	return nil
+}
+
+// splitLine 拆分配置行为小写的选项名与参数（支持 key=value 与双引号）
+func splitLine(line string) (string, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, nil
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil, nil
+	}
+	key := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	if strings.HasPrefix(rest, "=") {
+		rest = strings.TrimLeft(rest[1:], " \t")
+	}
+
+	var args []string
+	for rest != "" {
+		if rest[0] == '#' {
+			break
+		}
+		var arg string
+		if rest[0] == '"' {
+			closing := strings.IndexByte(rest[1:], '"')
+			if closing < 0 {
+				return "", nil, fmt.Errorf("引号未闭合")
+			}
+			arg, rest = rest[1:closing+1], rest[closing+2:]
+		} else if i := strings.IndexAny(rest, " \t"); i >= 0 {
+			arg, rest = rest[:i], rest[i:]
+		} else {
+			arg, rest = rest, ""
+		}
+		args = append(args, arg)
+		rest = strings.TrimLeft(rest, " \t")
+	}
+	return key, args, nil
+}
+
+// matchPatterns 检查别名是否匹配 Host 模式列表（不区分大小写）
+// 任一取反模式匹配时整体不匹配；exact 表示由 * 以外的模式匹配
+func matchPatterns(patterns []string, alias string) (matched, exact bool) {
+	alias = strings.ToLower(alias)
+	for _, p := range patterns {
+		negated := strings.HasPrefix(p, "!")
+		p = strings.ToLower(strings.TrimPrefix(p, "!"))
+		if !wildcardMatch(p, alias) {
+			continue
+		}
+		if negated {
+			return false, false
+		}
+		matched = true
+		if p != "*" {
+			exact = true
+		}
+	}
+	return matched, exact
+}
+
+// wildcardMatch 匹配 * 与 ? 通配符
+func wildcardMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if wildcardMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || pattern[0] != s[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return s == ""
+}
+
+// includePath 返回 Include 的路径，相对路径相对于 ~/.ssh
+func includePath(pattern string) string {
+	pattern = expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		return filepath.Join(expandHome("~/.ssh"), pattern)
+	}
+	return pattern
+}
+
+// expandPath 展开文件路径中的 ~ 与 % 标记
+func expandPath(path, host, remoteUser string) string {
+	return expandHome(expandTokens(path, host, remoteUser))
+}
+
+// expandTokens 展开 %h（主机）、%r（远程用户）、%u（本地用户）、%d（home 目录）与 %%
+func expandTokens(s, host, remoteUser string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	home, _ := os.UserHomeDir()
+	local := ""
+	if u, err := user.Current(); err == nil {
+		local = u.Username
+	}
+	if remoteUser == "" {
+		remoteUser = local
+	}
+	return strings.NewReplacer("%%", "%", "%h", host, "%r", remoteUser, "%u", local, "%d", home).Replace(s)
+}
+
+// expandHome 展开 ~ 为 home 目录
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
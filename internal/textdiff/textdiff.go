@@ -0,0 +1,184 @@
+// Package textdiff 生成按行比较的 unified diff
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpKind 行操作类型
+type OpKind int
+
+const (
+	OpEqual  OpKind = iota // 相同
+	OpDelete               // 仅在旧文本中
+	OpInsert               // 仅在新文本中
+)
+
+// Line diff 中的一行
+type Line struct {
+	Kind OpKind
+	Text string
+}
+
+// Lines 基于最长公共子序列比较两组行
+func Lines(a, b []string) []Line {
+	n, m := len(a), len(b)
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Kind: OpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Kind: OpDelete, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Kind: OpInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, Line{Kind: OpDelete, Text: a[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, Line{Kind: OpInsert, Text: b[j]})
+	}
+	return lines
+}
+
+// Unified 生成 unified diff 文本，context 为每处变更前后保留的上下文行数
+// 两段文本相同时返回空字符串
+func Unified(fromName, toName, from, to string, context int) string {
+	lines := Lines(splitLines(from), splitLines(to))
+
+	changed := false
+	for _, l := range lines {
+		if l.Kind != OpEqual {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	for _, h := range hunks(lines, context) {
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(h.fromStart, h.fromCount), hunkRange(h.toStart, h.toCount))
+		for _, l := range lines[h.start:h.end] {
+			switch l.Kind {
+			case OpEqual:
+				sb.WriteString(" ")
+			case OpDelete:
+				sb.WriteString("-")
+			case OpInsert:
+				sb.WriteString("+")
+			}
+			sb.WriteString(l.Text)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// hunk 一段变更块
+type hunk struct {
+	start, end           int // lines 下标范围 [start, end)
+	fromStart, fromCount int
+	toStart, toCount     int
+}
+
+// hunks 将变更按上下文行数合并为变更块
+func hunks(lines []Line, context int) []hunk {
+	var result []hunk
+	fromLine, toLine := 1, 1 // 当前行在旧/新文本中的行号
+	fromAt := make([]int, len(lines)+1)
+	toAt := make([]int, len(lines)+1)
+	for i, l := range lines {
+		fromAt[i], toAt[i] = fromLine, toLine
+		if l.Kind != OpInsert {
+			fromLine++
+		}
+		if l.Kind != OpDelete {
+			toLine++
+		}
+	}
+	fromAt[len(lines)], toAt[len(lines)] = fromLine, toLine
+
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == OpEqual {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		// 向后扩展，直到连续相同行超过 2*context
+		for end < len(lines) {
+			if lines[end].Kind != OpEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].Kind == OpEqual {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end = min(end+context, len(lines))
+				break
+			}
+			end = run
+		}
+
+		result = append(result, hunk{
+			start:     start,
+			end:       end,
+			fromStart: fromAt[start],
+			fromCount: fromAt[end] - fromAt[start],
+			toStart:   toAt[start],
+			toCount:   toAt[end] - toAt[start],
+		})
+		i = end
+	}
+	return result
+}
+
+// hunkRange 格式化变更块的行范围
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start-- // 空范围指向前一行
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines 按行拆分文本（忽略末尾换行）
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -15,6 +15,7 @@ type Task struct {
 	Status      types.TaskStatus
 	StartTime   time.Time
 	EndTime     time.Time
+	Hosts       []Task // 多服务器任务中各服务器的状态（显示在任务下方）
 }
 
 // Duration 返回任务耗时
@@ -28,12 +29,23 @@ func (t Task) Duration() time.Duration {
 	return t.EndTime.Sub(t.StartTime)
 }
 
+// setStatus 更新状态并记录开始/结束时间
+func (t *Task) setStatus(status types.TaskStatus) {
+	t.Status = status
+	if status == types.StatusRunning && t.StartTime.IsZero() {
+		t.StartTime = time.Now()
+	}
+	if status == types.StatusSuccess || status == types.StatusFailed {
+		t.EndTime = time.Now()
+	}
+}
+
 // Model Todo List 组件模型
 type Model struct {
 	tasks       []Task
 	width       int
 	height      int
-	scrollIndex int
+	scrollIndex int // 第一行可见的行（任务及其下方的服务器各占一行）
 	maxVisible  int
 	showAll     bool
 }
@@ -58,6 +70,7 @@ func (m *Model) SetSize(width, height int) {
 	m.height = height
 	// 根据高度计算可显示的任务数（减去标题和边框）
 	// 保底至少 1 行，避免小窗口时 maxVisible 维持默认值导致内容溢出
+	// 可显示的行数（多服务器任务下方的服务器各占一行）
 	m.maxVisible = height - 4
 	if m.maxVisible < 1 {
 		m.maxVisible = 1
@@ -84,13 +97,7 @@ func (m *Model) AddTask(task Task) {
 func (m *Model) UpdateTaskStatus(taskID string, status types.TaskStatus) {
 	for i := range m.tasks {
 		if m.tasks[i].ID == taskID {
-			m.tasks[i].Status = status
-			if status == types.StatusRunning && m.tasks[i].StartTime.IsZero() {
-				m.tasks[i].StartTime = time.Now()
-			}
-			if status == types.StatusSuccess || status == types.StatusFailed {
-				m.tasks[i].EndTime = time.Now()
-			}
+			m.tasks[i].setStatus(status)
 			// 确保最新变化的任务出现在可视区域
 			m.ensureVisibleIndex(i)
 			break
@@ -98,6 +105,48 @@ func (m *Model) UpdateTaskStatus(taskID string, status types.TaskStatus) {
 	}
 }
 
+// UpdateHostStatus 更新多服务器任务中单台服务器的状态，首次上报时添加到任务下方
+func (m *Model) UpdateHostStatus(taskID, host string, status types.TaskStatus) {
+	for i := range m.tasks {
+		if m.tasks[i].ID != taskID {
+			continue
+		}
+		task := &m.tasks[i]
+		idx := -1
+		for j := range task.Hosts {
+			if task.Hosts[j].Name == host {
+				idx = j
+				break
+			}
+		}
+		if idx < 0 {
+			task.Hosts = append(task.Hosts, Task{ID: taskID + "/" + host, Name: host, Status: types.StatusPending})
+			idx = len(task.Hosts) - 1
+		}
+		task.Hosts[idx].setStatus(status)
+		m.ensureVisibleRow(m.taskRow(i) + 1 + idx)
+		return
+	}
+}
+
+// RowCount 返回任务队列的总行数（任务及其下方的服务器）
+func (m Model) RowCount() int {
+	rows := len(m.tasks)
+	for _, task := range m.tasks {
+		rows += len(task.Hosts)
+	}
+	return rows
+}
+
+// taskRow 返回任务所在的行
+func (m Model) taskRow(index int) int {
+	row := index
+	for _, task := range m.tasks[:index] {
+		row += len(task.Hosts)
+	}
+	return row
+}
+
 // GetProgress 获取完成进度
 func (m Model) GetProgress() (completed, total int) {
 	total = len(m.tasks)
@@ -119,24 +168,29 @@ func (m *Model) EnsureVisible(taskID string) {
 	}
 }
 
-// ensureVisibleIndex 滚动窗口以包含指定下标
+// ensureVisibleIndex 滚动窗口以包含指定下标的任务
 func (m *Model) ensureVisibleIndex(idx int) {
+	m.ensureVisibleRow(m.taskRow(idx))
+}
+
+// ensureVisibleRow 滚动窗口以包含指定行
+func (m *Model) ensureVisibleRow(row int) {
 	if m.maxVisible <= 0 {
 		return
 	}
 	start := m.scrollIndex
 	end := start + m.maxVisible
-	if idx < start {
-		m.scrollIndex = idx
-	} else if idx >= end {
-		m.scrollIndex = idx - m.maxVisible + 1
+	if row < start {
+		m.scrollIndex = row
+	} else if row >= end {
+		m.scrollIndex = row - m.maxVisible + 1
 	}
 	m.normalizeScroll()
 }
 
-// normalizeScroll 约束 scrollIndex 边界
+// normalizeScroll 约束 scrollIndex 边界（按行计算）
 func (m *Model) normalizeScroll() {
-	maxStart := len(m.tasks) - m.maxVisible
+	maxStart := m.RowCount() - m.maxVisible
 	if maxStart < 0 {
 		maxStart = 0
 	}
@@ -166,6 +220,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case types.TaskStatusMsg:
 		m.UpdateTaskStatus(msg.TaskID, msg.Status)
+	case types.TaskHostStatusMsg:
+		m.UpdateHostStatus(msg.TaskID, msg.Host, msg.Status)
 	}
 
 	return m, nil
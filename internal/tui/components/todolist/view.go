@@ -16,33 +16,41 @@ func (m Model) View() string {
 		return m.renderEmpty()
 	}
 
-	visibleLimit := len(m.tasks)
+	// 多服务器任务的各服务器显示在任务下方，每台一行
+	var rows []string
+	for i, task := range m.tasks {
+		rows = append(rows, m.renderTask(task, i))
+		for j, host := range task.Hosts {
+			rows = append(rows, m.renderHost(host, j == len(task.Hosts)-1))
+		}
+	}
+
+	visibleLimit := len(rows)
 	if !m.showAll && visibleLimit > m.maxVisible {
 		visibleLimit = m.maxVisible
 	}
 
 	var b strings.Builder
 
-	// 计算可显示的任务范围
+	// 计算可显示的行范围
 	start := m.scrollIndex
 	end := start + visibleLimit
-	if end > len(m.tasks) {
-		end = len(m.tasks)
+	if end > len(rows) {
+		end = len(rows)
 	}
 
 	// 渲染任务列表
 	for i := start; i < end; i++ {
-		task := m.tasks[i]
-		b.WriteString(m.renderTask(task, i))
+		b.WriteString(rows[i])
 		if i < end-1 {
 			b.WriteString("\n")
 		}
 	}
 
 	// 如果有更多任务，显示滚动提示
-	if !m.showAll && len(m.tasks) > visibleLimit {
+	if !m.showAll && len(rows) > visibleLimit {
 		scrollInfo := fmt.Sprintf("  %s 显示 %d-%d / %d",
-			styles.IconBullet, start+1, end, len(m.tasks))
+			styles.IconBullet, start+1, end, len(rows))
 		b.WriteString("\n")
 		b.WriteString(lipgloss.NewStyle().Foreground(styles.MutedColor).Render(scrollInfo))
 	}
@@ -54,19 +62,7 @@ func (m Model) View() string {
 func (m Model) renderTask(task Task, index int) string {
 	icon := statusIcon(task.Status)
 	name := task.Name
-
-	// 根据状态设置名称样式
-	var nameStyle lipgloss.Style
-	switch task.Status {
-	case types.StatusSuccess:
-		nameStyle = styles.SuccessTextStyle
-	case types.StatusFailed:
-		nameStyle = styles.ErrorTextStyle
-	case types.StatusRunning:
-		nameStyle = styles.WarningTextStyle
-	default:
-		nameStyle = lipgloss.NewStyle().Foreground(styles.TextColor)
-	}
+	style := nameStyle(task.Status)
 
 	// 截断过长的名称
 	maxNameWidth := m.width - 30 // 留出状态和时间的空间
@@ -81,17 +77,45 @@ func (m Model) renderTask(task Task, index int) string {
 	statusText := m.formatStatus(task)
 
 	// 构建任务行
-	line := fmt.Sprintf("  %s %s", icon, nameStyle.Render(name))
+	line := fmt.Sprintf("  %s %s", icon, style.Render(name))
+
+	return m.alignStatus(line, statusText)
+}
 
-	// 添加状态文本（右对齐）
+// renderHost 渲染多服务器任务中的单台服务器（缩进显示在任务下方）
+func (m Model) renderHost(host Task, last bool) string {
+	branch := "├"
+	if last {
+		branch = "└"
+	}
+	prefix := lipgloss.NewStyle().Foreground(styles.MutedColor).Render("    " + branch)
+	line := fmt.Sprintf("%s %s %s", prefix, statusIcon(host.Status), nameStyle(host.Status).Render(host.Name))
+	return m.alignStatus(line, m.formatStatus(host))
+}
+
+// alignStatus 在行尾右对齐显示状态文本
+func (m Model) alignStatus(line, statusText string) string {
 	padding := m.width - lipgloss.Width(line) - lipgloss.Width(statusText) - 4
 	if padding < 1 {
 		padding = 1
 	}
-
 	return line + strings.Repeat(" ", padding) + statusText
 }
 
+// nameStyle 根据状态返回名称样式
+func nameStyle(status types.TaskStatus) lipgloss.Style {
+	switch status {
+	case types.StatusSuccess:
+		return styles.SuccessTextStyle
+	case types.StatusFailed:
+		return styles.ErrorTextStyle
+	case types.StatusRunning:
+		return styles.WarningTextStyle
+	default:
+		return lipgloss.NewStyle().Foreground(styles.TextColor)
+	}
+}
+
 // statusIcon 返回状态图标
 func statusIcon(s types.TaskStatus) string {
 	switch s {
@@ -12,10 +12,14 @@ type (
 	OutputBatchMsg      = types.OutputBatchMsg
 	TaskStatusMsg       = types.TaskStatusMsg
 	TaskProgressMsg     = types.TaskProgressMsg
+	TaskHostStatusMsg   = types.TaskHostStatusMsg
 	StageStartMsg       = types.StageStartMsg
 	StageCompleteMsg    = types.StageCompleteMsg
 	PipelineCompleteMsg = types.PipelineCompleteMsg
 	ErrorMsg            = types.ErrorMsg
+	PromptMsg           = types.PromptMsg
+	PromptReply         = types.PromptReply
+	PromptCancelMsg     = types.PromptCancelMsg
 	TickMsg             = types.TickMsg
 )
 
@@ -55,6 +59,7 @@ var (
 	NewOutputBatchMsg      = types.NewOutputBatchMsg
 	NewTaskStatusMsg       = types.NewTaskStatusMsg
 	NewTaskProgressMsg     = types.NewTaskProgressMsg
+	NewTaskHostStatusMsg   = types.NewTaskHostStatusMsg
 	NewStageStartMsg       = types.NewStageStartMsg
 	NewStageCompleteMsg    = types.NewStageCompleteMsg
 	NewPipelineCompleteMsg = types.NewPipelineCompleteMsg
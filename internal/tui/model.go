@@ -65,15 +65,15 @@ type Model struct {
 
 	// 帮助面板
 	showHelp bool
+
+	// 任务请求的输入框（显示时接管键盘输入）
+	prompt *promptState
 }
 
 // New 创建新的主 Model
-func New(cfg *config.Config) Model {
+func New(cfg *config.Config, p *pipeline.Pipeline) Model {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// 创建 pipeline
-	p := pipeline.New(cfg)
-
 	// 创建任务列表
 	tasks := make([]todolist.Task, 0)
 	for _, task := range p.GetAllTasks() {
@@ -130,6 +130,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.prompt != nil {
+			return m, m.handlePromptKey(msg)
+		}
 		if cmd := m.handleKeyMsg(msg); cmd != nil {
 			cmds = append(cmds, cmd)
 		}
@@ -158,6 +161,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TaskProgressMsg:
 		m.handleTaskProgressMsg(msg)
 
+	case TaskHostStatusMsg:
+		// 先更新任务队列，再按新的行数调整各区域高度
+		m.todoList.UpdateHostStatus(msg.TaskID, msg.Host, msg.Status)
+		m.updateComponentSizes()
+
+	case PromptMsg:
+		m.prompt = &promptState{msg: msg}
+		m.showHelp = false
+
+	case PromptCancelMsg:
+		if m.prompt != nil && m.prompt.msg.Reply == msg.Reply {
+			m.prompt = nil
+		}
+
 	case StageStartMsg:
 		m.currentStage = msg.StageIndex
 		m.statusBar.SetStage(msg.StageName, msg.StageIndex, len(m.pipeline.GetStages()))
@@ -337,8 +354,8 @@ func (m *Model) updateComponentSizes() {
 
 // getTodoHeight 获取任务队列区域高度（外框高度）
 func (m Model) getTodoHeight() int {
-	// 期望高度：任务数 + 标题/边框占用（最多 8 行）
-	desired := min(len(m.todoList.GetTasks())+2, 8)
+	// 期望高度：行数（任务及多服务器任务下方的服务器）+ 标题/边框占用（最多 8 行）
+	desired := min(m.todoList.RowCount()+2, 8)
 
 	// 运行态 UI（不含实时日志）真实固定占用：
 	// header(标题+分隔线+双空行)=4
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// promptState 当前显示的输入框（任务请求的交互式输入，如私钥密码短语）
+type promptState struct {
+	msg   PromptMsg
+	value []rune
+}
+
+// handlePromptKey 输入框显示时处理按键：Enter 提交、Esc 取消、Ctrl+C 退出，其余按键作为输入
+func (m *Model) handlePromptKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.replyPrompt(PromptReply{Cancelled: true})
+		m.quitting = true
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return tea.Quit
+	case tea.KeyEnter:
+		m.replyPrompt(PromptReply{Value: string(m.prompt.value)})
+	case tea.KeyEsc:
+		m.replyPrompt(PromptReply{Cancelled: true})
+	case tea.KeyBackspace:
+		if n := len(m.prompt.value); n > 0 {
+			m.prompt.value = m.prompt.value[:n-1]
+		}
+	case tea.KeyCtrlU:
+		m.prompt.value = nil
+	case tea.KeySpace:
+		m.prompt.value = append(m.prompt.value, ' ')
+	case tea.KeyRunes:
+		m.prompt.value = append(m.prompt.value, msg.Runes...)
+	}
+	return nil
+}
+
+// replyPrompt 返回输入结果并关闭输入框
+func (m *Model) replyPrompt(reply PromptReply) {
+	if m.prompt == nil {
+		return
+	}
+	select {
+	case m.prompt.msg.Reply <- reply:
+	default:
+	}
+	m.prompt = nil
+}
+
+// renderPrompt 渲染输入框（两行，与进度条占用的高度一致）
+func (m *Model) renderPrompt() string {
+	p := m.prompt
+	title := HighlightStyle.Render("🔐 "+p.msg.TaskName) + " " + p.msg.Question
+
+	value := string(p.value)
+	if p.msg.Secret {
+		value = strings.Repeat("•", len(p.value))
+	}
+	input := HighlightStyle.Render("> ") + value + HighlightStyle.Render("█") +
+		lipgloss.NewStyle().Foreground(MutedColor).Render("  [Enter] 确认  [Esc] 取消")
+
+	line := lipgloss.NewStyle().MaxWidth(max(m.width-2, 10))
+	return line.Render(title) + "\n" + line.Render(input)
+}
@@ -120,8 +120,12 @@ func (m *Model) renderRunningView() string {
 		b.WriteString("\n\n")
 	}
 
-	// 进度条（始终显示）
-	b.WriteString(m.progressBar.RenderWithTitle("Overall Progress"))
+	// 进度条（始终显示，有输入请求时替换为输入框）
+	if m.prompt != nil {
+		b.WriteString(m.renderPrompt())
+	} else {
+		b.WriteString(m.progressBar.RenderWithTitle("Overall Progress"))
+	}
 	b.WriteString("\n\n")
 
 	// 任务列表（始终显示）
@@ -103,6 +103,13 @@ type TaskProgressMsg struct {
 	Message string
 }
 
+// TaskHostStatusMsg 多服务器任务中单台服务器的状态消息
+type TaskHostStatusMsg struct {
+	TaskID string
+	Host   string
+	Status TaskStatus
+}
+
 // StageStartMsg 阶段开始消息
 type StageStartMsg struct {
 	StageIndex int
@@ -131,6 +138,26 @@ type ErrorMsg struct {
 	Message string
 }
 
+// PromptMsg 任务请求交互式输入（如加密私钥的密码短语），结果通过 Reply 返回
+type PromptMsg struct {
+	TaskID   string
+	TaskName string
+	Question string
+	Secret   bool // 输入不回显
+	Reply    chan<- PromptReply
+}
+
+// PromptReply 交互式输入的结果
+type PromptReply struct {
+	Value     string
+	Cancelled bool // 用户取消输入
+}
+
+// PromptCancelMsg 任务不再等待输入（如任务超时或被取消），TUI 关闭对应的输入框
+type PromptCancelMsg struct {
+	Reply chan<- PromptReply
+}
+
 // TickMsg 定时器消息
 type TickMsg time.Time
 
@@ -158,6 +185,11 @@ func NewTaskProgressMsg(taskID string, current, total int, message string) TaskP
 	return TaskProgressMsg{TaskID: taskID, Current: current, Total: total, Message: message}
 }
 
+// NewTaskHostStatusMsg 创建服务器状态消息
+func NewTaskHostStatusMsg(taskID, host string, status TaskStatus) TaskHostStatusMsg {
+	return TaskHostStatusMsg{TaskID: taskID, Host: host, Status: status}
+}
+
 // NewStageStartMsg 创建阶段开始消息
 func NewStageStartMsg(index int, name string) StageStartMsg {
 	return StageStartMsg{StageIndex: index, StageName: name}